@@ -0,0 +1,285 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// Aliases are alias.<name> settings, e.g.
+//
+//	[alias]
+//	fb = "new-branch --type feature"
+//	start = "new-branch {1} && exec npm ci"
+//
+// Each becomes a gwtm subcommand. Steps separated by && run in order, each
+// as its own gwtm invocation, stopping at the first that fails. {1}, {2}, ...
+// stand for the alias's arguments and {@} for all of them; an alias without
+// placeholders passes its arguments on to its last step.
+
+// aliasDepthEnv counts nested alias invocations so an alias that calls
+// itself fails instead of running forever. It is deliberately outside the
+// GWTM_* namespace, which is reserved for settings.
+const aliasDepthEnv = "GIT_WORKTREE_MANAGER_ALIAS_DEPTH"
+
+// maxAliasDepth is how deeply aliases may call other aliases
+const maxAliasDepth = 10
+
+// aliasStepFailed records that a step of an alias failed. The step has
+// reported its own error, so Execute fails without printing another.
+var aliasStepFailed bool
+
+var (
+	aliasNameRegex   = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	placeholderRegex = regexp.MustCompile(`\{([0-9]+|@)\}`)
+)
+
+// registerAliases adds a subcommand for every alias that applies in the
+// current directory. Aliases cannot replace built-in commands, so those are
+// skipped, as are aliases whose definition does not parse.
+func registerAliases() {
+	cwd, _ := os.Getwd()
+	for name, definition := range loadSettings(cwd).Family("alias") {
+		if !aliasNameRegex.MatchString(name) || isBuiltinCommand(name) {
+			continue
+		}
+		steps, err := parseAlias(definition)
+		if err != nil {
+			continue
+		}
+		rootCmd.AddCommand(newAliasCommand(name, definition, steps))
+	}
+}
+
+// isBuiltinCommand reports whether name is one of gwtm's own commands or
+// their aliases
+func isBuiltinCommand(name string) bool {
+	return slices.ContainsFunc(rootCmd.Commands(), func(c *cobra.Command) bool {
+		return c.Name() == name || c.HasAlias(name)
+	}) || name == "help" || name == "completion"
+}
+
+func newAliasCommand(name, definition string, steps [][]string) *cobra.Command {
+	return &cobra.Command{
+		Use:   name + " [args]...",
+		Short: "Alias for '" + definition + "'",
+		Long: fmt.Sprintf(`Alias for '%s', defined by the alias.%s setting.
+
+{1}, {2}, ... stand for the alias's arguments and {@} for all of them; without
+placeholders, arguments are passed on to the last step. Global flags such as
+--dry-run apply to every step.`, definition, name),
+		// Flags belong to the commands the alias runs
+		DisableFlagParsing: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) > 0 && (args[0] == "--help" || args[0] == "-h") {
+				cmd.Help()
+				return
+			}
+			runAlias(name, steps, args)
+		},
+	}
+}
+
+func runAlias(name string, steps [][]string, args []string) {
+	depth, _ := strconv.Atoi(os.Getenv(aliasDepthEnv))
+	if depth >= maxAliasDepth {
+		ui.PrintError(fmt.Errorf("alias %s is nested more than %d deep", name, maxAliasDepth), "Check alias."+name+" for an alias that calls itself")
+		return
+	}
+
+	globals, args := splitGlobalFlags(args)
+	expanded, err := expandAlias(steps, args)
+	if err != nil {
+		ui.PrintError(fmt.Errorf("alias %s: %w", name, err), "Run 'gwtm "+name+" --help' to see its definition")
+		return
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		ui.PrintError(fmt.Errorf("failed to find the gwtm binary: %w", err), "Run the alias's commands directly")
+		return
+	}
+
+	for i, step := range expanded {
+		cmd := exec.Command(self, append(slices.Clone(globals), step...)...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%d", aliasDepthEnv, depth+1))
+
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+			continue
+		case errors.As(err, &exitErr):
+			aliasStepFailed = true
+			if i < len(expanded)-1 {
+				ui.PrintStatus("⏭️", fmt.Sprintf("Skipped the remaining steps of alias %s", name))
+			}
+		default:
+			ui.PrintError(fmt.Errorf("alias %s: failed to run 'gwtm %s': %w", name, strings.Join(step, " "), err), "Run the alias's commands directly")
+		}
+		return
+	}
+}
+
+// splitGlobalFlags separates gwtm's global flags, which apply to every step
+// of an alias, from the arguments the alias passes on. Everything after --
+// is passed on as it is.
+func splitGlobalFlags(args []string) (globals, rest []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			return globals, append(rest, args[i:]...)
+		}
+		if isGlobalFlag(arg) {
+			globals = append(globals, arg)
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	return globals, rest
+}
+
+// isGlobalFlag reports whether arg is one of rootCmd's boolean persistent
+// flags, e.g. --dry-run, --dry-run=false or -y
+func isGlobalFlag(arg string) bool {
+	switch {
+	case strings.HasPrefix(arg, "--"):
+		name, _, _ := strings.Cut(arg[2:], "=")
+		return name != "" && rootCmd.PersistentFlags().Lookup(name) != nil
+	case strings.HasPrefix(arg, "-") && len(arg) == 2:
+		return rootCmd.PersistentFlags().ShorthandLookup(arg[1:]) != nil
+	default:
+		return false
+	}
+}
+
+// parseAlias splits an alias definition into steps and each step into
+// words. Words may be quoted with '...' or "..." and characters escaped with
+// a backslash, as in a shell; && outside quotes separates steps.
+func parseAlias(definition string) ([][]string, error) {
+	var steps [][]string
+	var step []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	endWord := func() {
+		if inWord {
+			step = append(step, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endStep := func() error {
+		endWord()
+		if len(step) == 0 {
+			return fmt.Errorf("empty step in %q", definition)
+		}
+		steps = append(steps, step)
+		step = nil
+		return nil
+	}
+
+	runes := []rune(definition)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\'):
+				i++
+				word.WriteRune(runes[i])
+			default:
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case r == '&' && i+1 < len(runes) && runes[i+1] == '&':
+			i++
+			if err := endStep(); err != nil {
+				return nil, err
+			}
+		case r == ' ' || r == '\t' || r == '\n':
+			endWord()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, definition)
+	}
+	if err := endStep(); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// expandAlias replaces the placeholders in steps with args: {N} with the Nth
+// argument and {@} with all of them, as separate words when it is a word of
+// its own. Without placeholders, args are added to the last step.
+func expandAlias(steps [][]string, args []string) ([][]string, error) {
+	usesPlaceholders := false
+	expanded := make([][]string, len(steps))
+
+	for i, step := range steps {
+		for _, word := range step {
+			if word == "{@}" {
+				usesPlaceholders = true
+				expanded[i] = append(expanded[i], args...)
+				continue
+			}
+
+			var missing error
+			word = placeholderRegex.ReplaceAllStringFunc(word, func(p string) string {
+				usesPlaceholders = true
+				ref := p[1 : len(p)-1]
+				if ref == "@" {
+					return strings.Join(args, " ")
+				}
+				n, _ := strconv.Atoi(ref)
+				if n < 1 {
+					missing = fmt.Errorf("%s is not an argument; they are numbered from {1}", p)
+					return p
+				}
+				if n > len(args) {
+					missing = fmt.Errorf("%s needs at least %d argument(s), got %d", p, n, len(args))
+					return p
+				}
+				return args[n-1]
+			})
+			if missing != nil {
+				return nil, missing
+			}
+			expanded[i] = append(expanded[i], word)
+		}
+	}
+
+	if !usesPlaceholders {
+		last := len(expanded) - 1
+		expanded[last] = append(expanded[last], args...)
+	}
+	return expanded, nil
+}
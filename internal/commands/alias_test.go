@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAlias(t *testing.T) {
+	tests := []struct {
+		definition string
+		want       [][]string
+		wantErr    string
+	}{
+		{
+			definition: "new-branch --type feature",
+			want:       [][]string{{"new-branch", "--type", "feature"}},
+		},
+		{
+			definition: "new-branch {1} && exec npm ci",
+			want:       [][]string{{"new-branch", "{1}"}, {"exec", "npm", "ci"}},
+		},
+		{
+			definition: `exec -- sh -c 'echo "$GWTM_BRANCH" && ls'&&list`,
+			want:       [][]string{{"exec", "--", "sh", "-c", `echo "$GWTM_BRANCH" && ls`}, {"list"}},
+		},
+		{
+			definition: `new-branch "feat/{1} \"x\"" a\ b ''`,
+			want:       [][]string{{"new-branch", `feat/{1} "x"`, "a b", ""}},
+		},
+		{definition: "sync &&", wantErr: "empty step"},
+		{definition: "  ", wantErr: "empty step"},
+		{definition: "exec 'echo", wantErr: "unterminated ' quote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.definition, func(t *testing.T) {
+			got, err := parseAlias(tt.definition)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseAlias() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAlias() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   [][]string
+		args    []string
+		want    [][]string
+		wantErr string
+	}{
+		{
+			name:  "arguments go to the last step without placeholders",
+			steps: [][]string{{"sync"}, {"new-branch", "--type", "feature"}},
+			args:  []string{"login", "--from", "main"},
+			want:  [][]string{{"sync"}, {"new-branch", "--type", "feature", "login", "--from", "main"}},
+		},
+		{
+			name:  "numbered placeholders",
+			steps: [][]string{{"new-branch", "feat/{1}"}, {"exec", "--", "echo", "{2}"}},
+			args:  []string{"login", "hi"},
+			want:  [][]string{{"new-branch", "feat/login"}, {"exec", "--", "echo", "hi"}},
+		},
+		{
+			name:  "{@} as a word keeps arguments apart",
+			steps: [][]string{{"exec", "--", "{@}"}, {"list"}},
+			args:  []string{"npm", "ci"},
+			want:  [][]string{{"exec", "--", "npm", "ci"}, {"list"}},
+		},
+		{
+			name:  "{@} inside a word joins arguments",
+			steps: [][]string{{"exec", "--", "sh", "-c", "echo {@}"}},
+			args:  []string{"a", "b"},
+			want:  [][]string{{"exec", "--", "sh", "-c", "echo a b"}},
+		},
+		{
+			name:    "missing argument",
+			steps:   [][]string{{"new-branch", "{2}"}},
+			args:    []string{"one"},
+			wantErr: "at least 2 argument(s)",
+		},
+		{
+			name:    "arguments start at 1",
+			steps:   [][]string{{"new-branch", "{0}"}},
+			wantErr: "numbered from {1}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandAlias(tt.steps, tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expandAlias() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandAlias() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitGlobalFlags(t *testing.T) {
+	globals, rest := splitGlobalFlags([]string{"--dry-run", "login", "-y", "--type", "fix", "--no-hooks=true", "--", "--yes"})
+	if want := []string{"--dry-run", "-y", "--no-hooks=true"}; !reflect.DeepEqual(globals, want) {
+		t.Errorf("splitGlobalFlags() globals = %q, want %q", globals, want)
+	}
+	if want := []string{"login", "--type", "fix", "--", "--yes"}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("splitGlobalFlags() rest = %q, want %q", rest, want)
+	}
+}
@@ -0,0 +1,280 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+)
+
+// manifestEntry describes one repository in a bootstrap manifest.
+//
+// Manifests are YAML, with a list of repositories under repos:
+//
+//	repos:
+//	  - url: acme/webapp              # any form accepted by gwtm setup
+//	    name: webapp                  # optional, defaults to dir or the repository name
+//	    dir: clients/webapp           # optional, defaults to the name
+//	    branch: develop               # optional, defaults to the default branch
+//	    config:                       # optional git config settings
+//	      pull.rebase: false
+//	    reference: ~/mirrors/webapp   # optional local repository to borrow objects from
+//	    submodules: true              # optional, overrides --submodules
+//	    profile: norebase             # optional, overrides --profile
+type manifestEntry struct {
+	Name       string
+	URL        string
+	Dir        string
+	Branch     string
+	Config     map[string]string
+	Reference  string
+	Submodules *bool  // nil when the manifest does not say
+	Profile    string // Empty when the manifest does not say
+}
+
+// parseManifest reads the repositories listed in a bootstrap manifest, in file order.
+func parseManifest(path string) ([]manifestEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	doc, err := parseYAML(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	fail := func(node *yamlNode, format string, args ...any) ([]manifestEntry, error) {
+		return nil, fmt.Errorf("%s: line %d: %s", path, node.Line, fmt.Sprintf(format, args...))
+	}
+
+	if doc.Kind != yamlMap {
+		return fail(doc, "expected repos: at the top of the manifest")
+	}
+	var list *yamlNode
+	for _, pair := range doc.Map {
+		if pair.Key != "repos" {
+			return fail(doc, "unknown key %q; the repositories go under repos:", pair.Key)
+		}
+		list = pair.Value
+	}
+	if list == nil || (list.Kind == yamlScalar && list.Scalar == "") {
+		return nil, nil
+	}
+	if list.Kind != yamlSeq {
+		return fail(list, "repos must be a list, one '- url: ...' item per repository")
+	}
+
+	repos := make([]manifestEntry, 0, len(list.Seq))
+	names := make(map[string]bool)
+	for _, item := range list.Seq {
+		if item.Kind != yamlMap {
+			return fail(item, "each repository must be a mapping with at least url:")
+		}
+
+		repo := manifestEntry{Config: map[string]string{}}
+		for _, pair := range item.Map {
+			value := pair.Value
+			if pair.Key == "config" {
+				if value.Kind == yamlScalar && value.Scalar == "" {
+					continue
+				}
+				if value.Kind != yamlMap {
+					return fail(value, "config must map git config keys to values")
+				}
+				for _, setting := range value.Map {
+					if setting.Value.Kind != yamlScalar {
+						return fail(setting.Value, "config %s must have a single value", setting.Key)
+					}
+					repo.Config[setting.Key] = setting.Value.Scalar
+				}
+				continue
+			}
+
+			if value.Kind != yamlScalar {
+				return fail(value, "%s must be a single value", pair.Key)
+			}
+			switch pair.Key {
+			case "url":
+				repo.URL = value.Scalar
+			case "name":
+				repo.Name = value.Scalar
+			case "dir":
+				repo.Dir = value.Scalar
+			case "branch":
+				repo.Branch = value.Scalar
+			case "reference":
+				repo.Reference = value.Scalar
+			case "profile":
+				repo.Profile = value.Scalar
+			case "submodules":
+				enabled, ok := config.ParseBool(value.Scalar)
+				if !ok {
+					return fail(value, "submodules must be true or false, got %q", value.Scalar)
+				}
+				repo.Submodules = &enabled
+			default:
+				return fail(value, "unknown field %q", pair.Key)
+			}
+		}
+
+		if repo.URL == "" {
+			return fail(item, "missing url")
+		}
+		if repo.Name == "" {
+			repo.Name = repo.Dir
+		}
+		if repo.Name == "" {
+			repo.Name = repoNameFromPath(strings.ReplaceAll(repo.URL, ":", "/"), "/")
+		}
+		if repo.Dir == "" {
+			repo.Dir = repo.Name
+		}
+		if names[repo.Name] {
+			return fail(item, "%q is listed twice; give one of them another name or dir", repo.Name)
+		}
+		names[repo.Name] = true
+		repos = append(repos, repo)
+	}
+
+	return repos, nil
+}
+
+// bootstrapResult records the outcome of setting up one manifest entry
+type bootstrapResult struct {
+	Name    string
+	Skipped bool
+	Err     error
+}
+
+// runBootstrap sets up every repository in the manifest, running at most jobs
+// setups at once, and prints a summary of the outcome. defaults supplies the
+// settings, and the object sharing, submodule and profile options for entries
+// that do not set their own.
+func runBootstrap(manifestPath string, jobs int, defaults setupOptions) {
+	repos, err := parseManifest(manifestPath)
+	if err != nil {
+		printGuidedError(err, "Check the manifest syntax — see 'gwtm setup --help'")
+		return
+	}
+	if len(repos) == 0 {
+		ui.PrintStatus("ℹ️", "Manifest lists no repositories")
+		return
+	}
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > len(repos) {
+		jobs = len(repos)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		ui.PrintError(err, "Failed to determine current directory")
+		return
+	}
+
+	specOpts := repoSpecOptionsFrom(defaults.Settings)
+	results := make([]bootstrapResult, len(repos))
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+
+	ui.PrintStatus("🚀", fmt.Sprintf("Bootstrapping %d repositories (%d at a time)", len(repos), jobs))
+
+	for i, repo := range repos {
+		wg.Add(1)
+		go func(i int, repo manifestEntry) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = bootstrapRepo(repo, cwd, specOpts, defaults)
+		}(i, repo)
+	}
+	wg.Wait()
+
+	var created, skipped, failed int
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+		case result.Skipped:
+			skipped++
+		default:
+			created++
+		}
+	}
+
+	createdLabel := "set up"
+	if GetDryRun() {
+		createdLabel = "would be set up"
+	}
+	ui.PrintStatus("📊", fmt.Sprintf("Bootstrap summary: %d %s, %d skipped, %d failed", created, createdLabel, skipped, failed))
+	for _, result := range results {
+		if result.Err != nil {
+			printGuidedError(fmt.Errorf("%s: %w", result.Name, result.Err), "Fix the problem and rerun — completed repositories will be skipped")
+		}
+	}
+}
+
+// bootstrapRepo sets up a single manifest entry relative to baseDir
+func bootstrapRepo(repo manifestEntry, baseDir string, specOpts repoSpecOptions, defaults setupOptions) bootstrapResult {
+	status := func(emoji, message string) {
+		ui.PrintStatus(emoji, "["+repo.Name+"] "+message)
+	}
+
+	url, _, err := parseRepoSpec(repo.URL, specOpts)
+	if err != nil {
+		return bootstrapResult{Name: repo.Name, Err: err}
+	}
+
+	repoDir := repo.Dir
+	if !filepath.IsAbs(repoDir) {
+		repoDir = filepath.Join(baseDir, repoDir)
+	}
+
+	if _, err := os.Stat(repoDir); !os.IsNotExist(err) {
+		status("⏭️", "Skipping — "+repoDir+" already exists")
+		return bootstrapResult{Name: repo.Name, Skipped: true}
+	}
+
+	if GetDryRun() {
+		ui.PrintDryRun(fmt.Sprintf("[%s] Would set up %s in %s", repo.Name, url, repoDir))
+		return bootstrapResult{Name: repo.Name}
+	}
+
+	opts := setupOptions{
+		Branch:      repo.Branch,
+		Config:      repo.Config,
+		Reference:   defaults.Reference,
+		UseCache:    defaults.UseCache,
+		Submodules:  defaults.Submodules,
+		Maintenance: defaults.Maintenance,
+		Profile:     defaults.Profile,
+		Settings:    defaults.Settings,
+	}
+	if repo.Submodules != nil {
+		opts.Submodules = *repo.Submodules
+	}
+	if repo.Profile != "" {
+		opts.Profile = repo.Profile
+	}
+	if repo.Reference != "" {
+		reference, err := expandLocalPath(repo.Reference)
+		if err != nil {
+			return bootstrapResult{Name: repo.Name, Err: err}
+		}
+		opts.Reference = reference
+	}
+	if branch, err := setupProject(url, repoDir, opts, status); err != nil {
+		if branch != "" {
+			status("⚠️", "Set up in "+repoDir+", but not completely")
+		}
+		return bootstrapResult{Name: repo.Name, Err: err}
+	}
+
+	status("✅", "Ready in "+repoDir)
+	return bootstrapResult{Name: repo.Name}
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []manifestEntry
+		wantErr string
+	}{
+		{
+			name: "full and minimal entries",
+			content: `# New hire workspace
+repos:
+  - url: acme/webapp   # shorthand
+    dir: clients/webapp
+    branch: develop
+    config:
+      pull.rebase: false
+      "url.git@github.com:.insteadOf": https://github.com/
+    profile: norebase
+    submodules: yes
+  - url: "git@gitlab.com:acme/api.git"
+  -
+    name: 'tools'
+    url: https://example.com/it's/tools.git
+`,
+			want: []manifestEntry{
+				{
+					Name:       "clients/webapp",
+					URL:        "acme/webapp",
+					Dir:        "clients/webapp",
+					Branch:     "develop",
+					Config:     map[string]string{"pull.rebase": "false", "url.git@github.com:.insteadOf": "https://github.com/"},
+					Profile:    "norebase",
+					Submodules: boolPtr(true),
+				},
+				{
+					Name:   "api",
+					URL:    "git@gitlab.com:acme/api.git",
+					Dir:    "api",
+					Config: map[string]string{},
+				},
+				{
+					Name:   "tools",
+					URL:    "https://example.com/it's/tools.git",
+					Dir:    "tools",
+					Config: map[string]string{},
+				},
+			},
+		},
+		{
+			name:    "empty manifest",
+			content: "repos:\n",
+		},
+		{
+			name:    "missing url",
+			content: "repos:\n  - dir: webapp\n",
+			wantErr: "line 2: missing url",
+		},
+		{
+			name:    "unknown field",
+			content: "repos:\n  - url: acme/webapp\n    branc: develop\n",
+			wantErr: `line 3: unknown field "branc"`,
+		},
+		{
+			name:    "config that is not a mapping",
+			content: "repos:\n  - url: acme/webapp\n    config: pull.rebase=false\n",
+			wantErr: "config must map",
+		},
+		{
+			name:    "submodules not a boolean",
+			content: "repos:\n  - url: acme/webapp\n    submodules: maybe\n",
+			wantErr: "submodules must be true or false",
+		},
+		{
+			name:    "repositories not under repos",
+			content: "projects:\n  - url: acme/webapp\n",
+			wantErr: `unknown key "projects"`,
+		},
+		{
+			name:    "same directory twice",
+			content: "repos:\n  - url: acme/webapp\n  - url: other/webapp\n",
+			wantErr: `"webapp" is listed twice`,
+		},
+		{
+			name:    "git config syntax",
+			content: "[repo \"webapp\"]\nurl = acme/webapp\n",
+			wantErr: "line 1: expected a list item or key: value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "repos.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write manifest: %v", err)
+			}
+
+			got, err := parseManifest(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseManifest() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseManifest() error = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseManifest() returned %d entries, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				entry := got[i]
+				if entry.Name != want.Name || entry.URL != want.URL || entry.Dir != want.Dir || entry.Branch != want.Branch || entry.Profile != want.Profile {
+					t.Errorf("parseManifest()[%d] = %+v, want %+v", i, entry, want)
+				}
+				if deref(entry.Submodules) != deref(want.Submodules) {
+					t.Errorf("parseManifest()[%d].Submodules = %v, want %v", i, deref(entry.Submodules), deref(want.Submodules))
+				}
+				if len(entry.Config) != len(want.Config) {
+					t.Errorf("parseManifest()[%d].Config = %v, want %v", i, entry.Config, want.Config)
+				}
+				for key, value := range want.Config {
+					if entry.Config[key] != value {
+						t.Errorf("parseManifest()[%d].Config[%q] = %q, want %q", i, key, entry.Config[key], value)
+					}
+				}
+			}
+		})
+	}
+}
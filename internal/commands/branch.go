@@ -4,34 +4,66 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/hooks"
+	"github.com/lucasmodrich/git-worktree-manager/internal/include"
+	"github.com/lucasmodrich/git-worktree-manager/internal/steps"
 	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var branchCmd = &cobra.Command{
-	Use:   "new-branch <branch-name> [base-branch]",
+	Use:   "new-branch <branch-name> [base-branch] | --type <type> --ticket <id> <description> [base-branch] | --pr <number>",
 	Short: "Create new branch worktree",
-	Long:  "Create a new worktree for a branch. If the branch doesn't exist, it will be created from the base branch (or default branch).",
-	Args:  cobra.RangeArgs(1, 2),
-	Run:   runBranch,
+	Long: `Create a new worktree for a branch. If the branch doesn't exist, it will be created from the base branch (or default branch).
+Slashes in branch names become hyphens in the worktree directory name (feat/login → feat-login).
+
+With --type and/or --ticket, the first argument is a description and the branch name is
+built from the gwtm.branch.template setting (default "{type}/{ticket}-{slug}"), e.g.
+--type feat --ticket ABC-12 "add login" → feat/ABC-12-add-login.
+
+With --pr, fetch a pull request (GitHub) or merge request (GitLab) from origin into
+a branch named pr-<number> and create a worktree for it. Use --update to fetch the
+author's latest pushes into an existing pull request worktree.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("pr") {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.RangeArgs(1, 2)(cmd, args)
+	},
+	Run: runBranch,
 }
 
 func init() {
+	branchCmd.Flags().Bool("push", false, "Push the branch to origin without asking")
+	branchCmd.Flags().Bool("no-push", false, "Don't push the branch to origin")
+	branchCmd.Flags().Bool("no-fetch", false, "Work from local refs without contacting origin")
+	branchCmd.Flags().Bool("track-remote", false, "Create a tracking branch without asking when the branch only exists on origin")
+	branchCmd.Flags().String("type", "", "Build the branch name from a template: the branch type, e.g. feat or fix")
+	branchCmd.Flags().String("ticket", "", "Build the branch name from a template: the ticket id, e.g. ABC-12")
+	branchCmd.Flags().Int("pr", 0, "Create a worktree for this pull request number instead of a branch")
+	branchCmd.Flags().Bool("update", false, "With --pr, fetch the latest pull request changes into an existing worktree")
+	addWorktreeExtrasFlags(branchCmd)
 	rootCmd.AddCommand(branchCmd)
 }
 
 func runBranch(cmd *cobra.Command, args []string) {
-	branchName := args[0]
-
-	// Reject slashes — they cause directory path issues on all platforms
-	if strings.ContainsAny(branchName, `/\`) {
-		ui.PrintError(
-			fmt.Errorf("branch name %q contains a slash", branchName),
-			"Use hyphens instead of slashes (e.g. 'feature-my-thing' not 'feature/my-thing')",
-		)
+	update, _ := cmd.Flags().GetBool("update")
+	if cmd.Flags().Changed("pr") {
+		root, err := findWorktreeRoot()
+		if err != nil {
+			ui.PrintError(err, "Run this command from within a worktree-managed repository")
+			return
+		}
+		number, _ := cmd.Flags().GetInt("pr")
+		runPullRequest(cmd, root, number, update)
+		return
+	}
+	if update {
+		ui.PrintError(fmt.Errorf("--update only applies to --pr"), "Use 'gwtm new-branch --pr <number> --update'")
 		return
 	}
 
@@ -42,56 +74,138 @@ func runBranch(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	policy, err := loadNamingPolicy(root)
+	if err != nil {
+		ui.PrintError(err, "Fix the branch.* settings with 'gwtm config set' (--project for this project only); 'gwtm config list --show-origin' shows where they come from")
+		return
+	}
+
+	branchName := args[0]
+	branchType, _ := cmd.Flags().GetString("type")
+	ticket, _ := cmd.Flags().GetString("ticket")
+	if branchType != "" || ticket != "" {
+		// The first argument is a description to build the name from
+		branchName = renderBranchName(policy.Template, branchType, ticket, args[0], policy.MaxLength)
+		ui.PrintStatus("🏷️", "Branch name: "+branchName)
+	}
+
+	// Validate before touching anything
+	if err := git.NewClient(root).CheckBranchName(branchName); err != nil {
+		ui.PrintError(err, "Branch names can't contain spaces, '..', '~', '^', ':' or end in '.lock'")
+		return
+	}
+	if err := policy.validate(branchName); err != nil {
+		ui.PrintError(err, policy.describe())
+		return
+	}
+
 	// baseBranch is kept local so it doesn't bleed between invocations
 	var baseBranch string
 	if len(args) > 1 {
 		baseBranch = args[1]
 	}
 
+	noFetch, _ := cmd.Flags().GetBool("no-fetch")
+	push, err := boolChoice(cmd, "push")
+	if err != nil {
+		ui.PrintError(err, "Choose either --push or --no-push")
+		return
+	}
+	var trackRemote *bool
+	if cmd.Flags().Changed("track-remote") {
+		value, _ := cmd.Flags().GetBool("track-remote")
+		trackRemote = &value
+	}
+
+	worktreePath := filepath.Join(root, worktreeDirName(branchName))
+	if err := checkWorktreeDir(root, worktreePath, branchName); err != nil {
+		ui.PrintError(err, "Choose another branch name, or remove the existing worktree with 'gwtm remove'")
+		return
+	}
+
+	extras, err := loadWorktreeExtras(cmd, root, worktreePath)
+	if err != nil {
+		printGuidedError(err, "Check the new-branch flags")
+		return
+	}
+
 	client := git.NewClient(root)
 	client.DryRun = GetDryRun()
 
 	if client.DryRun {
-		ui.PrintDryRun("Would fetch latest from origin")
+		if !noFetch {
+			ui.PrintDryRun("Would fetch '" + branchName + "' and its base branch from origin")
+		}
 		ui.PrintDryRun("Would create new branch '" + branchName + "'")
-		ui.PrintDryRun("Would push new branch '" + branchName + "' to origin")
+		if push == nil || *push {
+			ui.PrintDryRun("Would push new branch '" + branchName + "' to origin")
+		}
 		ui.PrintDryRun("Would create worktree for '" + branchName + "'")
-		return
-	}
-
-	// Fetch latest from origin
-	ui.PrintStatus("📡", "Fetching latest from origin")
-	if err := client.Fetch(true, false); err != nil {
-		ui.PrintError(err, "Check network connection")
+		extras.printDryRun(root, worktreePath)
 		return
 	}
 
 	branchExistsLocal := client.BranchExists(branchName, false)
 	branchExistsRemote := client.BranchExists(branchName, true)
 
+	var offline bool
+	if noFetch {
+		ui.PrintStatus("⏭️", "Skipping fetch — using local refs")
+	} else {
+		fetchBase := baseBranch
+		if fetchBase == "" {
+			// Only local refs, since origin may be unreachable. When they
+			// cannot tell, just the branch is fetched and the base is
+			// detected once the fetch shows whether origin can be asked.
+			if branch, err := client.LocalDefaultBranch(); err == nil {
+				fetchBase = branch
+			}
+		}
+
+		onRemote, err := fetchBranches(client, branchName, fetchBase)
+		switch {
+		case git.IsNetworkError(err):
+			offline = true
+			ui.PrintStatus("⚠️", "origin is unreachable — continuing offline with local refs")
+		case err != nil:
+			ui.PrintError(err, "Check the remote, or use --no-fetch to work from local refs")
+			return
+		default:
+			// origin's answer beats a possibly stale remote-tracking branch
+			branchExistsRemote = slices.Contains(onRemote, branchName)
+		}
+	}
+
+	// Completed steps are undone if a later one fails, so a failed run leaves
+	// nothing half-made behind
+	runner := steps.NewRunner(ui.PrintStatus)
+	fail := func(err error, guidance string) {
+		runner.PrintSummary()
+		ui.PrintError(err, guidance)
+	}
+
 	var shouldPush bool
 
 	if branchExistsLocal {
 		ui.PrintStatus("📂", "Branch '"+branchName+"' exists locally — creating worktree from it")
 
-		if !branchExistsRemote {
+		// Offline we can't tell whether origin has it, and couldn't push anyway
+		if !branchExistsRemote && !offline {
 			ui.PrintStatus("⚠️", "Branch '"+branchName+"' not found on remote")
 
-			answer, err := ui.PromptYesNo("☁️  Push branch to remote?", os.Stdin)
+			answer, err := confirm("☁️ ", "Push branch to remote?", push)
 			if err != nil {
-				ui.PrintError(err, "Invalid input")
+				ui.PrintError(err, confirmGuidance("--push or --no-push"))
 				return
 			}
-			if answer {
-				shouldPush = true
-			}
+			shouldPush = answer
 		}
 	} else if branchExistsRemote {
 		ui.PrintStatus("☁️", "Branch '"+branchName+"' exists on remote but not locally")
 
-		answer, err := ui.PromptYesNo("📥 Fetch and create worktree from remote branch?", os.Stdin)
+		answer, err := confirm("📥", "Fetch and create worktree from remote branch?", trackRemote)
 		if err != nil {
-			ui.PrintError(err, "Invalid input")
+			ui.PrintError(err, confirmGuidance("--track-remote"))
 			return
 		}
 		if !answer {
@@ -99,41 +213,161 @@ func runBranch(cmd *cobra.Command, args []string) {
 			return
 		}
 
-		if err := client.CreateBranch(branchName, "origin/"+branchName); err != nil {
-			ui.PrintError(err, "Failed to create tracking branch")
+		err = runner.Run(steps.Step{
+			Name: "Creating branch '" + branchName + "' tracking 'origin/" + branchName + "'",
+			Do:   func() error { return client.CreateBranch(branchName, "origin/"+branchName) },
+			Undo: func() error { return client.DeleteBranch(branchName, true) },
+		})
+		if err != nil {
+			fail(err, "Failed to create tracking branch")
 			return
 		}
 	} else {
 		if baseBranch == "" {
-			baseBranch, err = client.DetectDefaultBranch()
+			if offline || noFetch {
+				baseBranch, err = client.LocalDefaultBranch()
+			} else {
+				baseBranch, err = client.DetectDefaultBranch()
+			}
 			if err != nil {
-				ui.PrintError(err, "Could not detect default branch")
+				ui.PrintError(err, "Pass the branch to start from as the second argument")
 				return
 			}
 		}
 
-		ui.PrintStatus("🌱", fmt.Sprintf("Creating new branch '%s' from '%s'", branchName, baseBranch))
-
-		if err := client.CreateBranch(branchName, baseBranch); err != nil {
-			ui.PrintError(err, "Failed to create branch")
+		err = runner.Run(steps.Step{
+			Emoji: "🌱",
+			Name:  fmt.Sprintf("Creating new branch '%s' from '%s'", branchName, baseBranch),
+			Do:    func() error { return client.CreateBranch(branchName, baseBranch) },
+			Undo:  func() error { return client.DeleteBranch(branchName, true) },
+		})
+		if err != nil {
+			fail(err, "Failed to create branch")
 			return
 		}
-		shouldPush = true
+		// A brand-new branch is pushed unless asked not to
+		shouldPush = push == nil || *push
 	}
 
-	worktreePath := filepath.Join(root, branchName)
-	if err := client.WorktreeAdd(worktreePath, branchName, false); err != nil {
-		ui.PrintError(err, "Failed to create worktree")
+	err = runner.Run(steps.Step{
+		Name: "Creating worktree at " + worktreePath,
+		Do:   func() error { return client.WorktreeAdd(worktreePath, branchName, false) },
+	})
+	if err != nil {
+		fail(err, "Failed to create worktree")
 		return
 	}
 
+	finishWorktree(root, worktreePath, branchName, baseBranch, extras)
+
+	if shouldPush && offline {
+		ui.PrintStatus("⚠️", "Not pushing while offline — run 'git push -u origin "+branchName+"' later")
+		shouldPush = false
+	}
+
 	if shouldPush {
-		ui.PrintStatus("☁️", "Pushing new branch '"+branchName+"' to origin")
-		if err := client.Push(branchName, true); err != nil {
-			ui.PrintError(err, "Failed to push branch to remote")
+		// The worktree is usable without the push, so a failure keeps it
+		err = runner.Run(steps.Step{
+			Emoji:    "☁️",
+			Name:     "Pushing new branch '" + branchName + "' to origin",
+			Do:       func() error { return client.Push(branchName, true) },
+			Optional: true,
+		})
+		if err != nil {
+			fail(err, "The worktree was kept — push it later with 'git push -u origin "+branchName+"'")
 			return
 		}
 	}
 
 	ui.PrintStatus("✅", "Worktree for '"+branchName+"' is ready at: "+worktreePath)
 }
+
+// fetchBranches fetches whichever of the named branches exist on origin,
+// instead of everything, and returns their names. Empty names are ignored.
+// A git.IsNetworkError error means origin could not be reached.
+func fetchBranches(client *git.Client, names ...string) ([]string, error) {
+	names = slices.DeleteFunc(slices.Compact(names), func(name string) bool { return name == "" })
+
+	ui.PrintStatus("📡", "Fetching "+strings.Join(names, ", ")+" from origin")
+	onRemote, err := client.RemoteBranches("origin", names...)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.FetchBranches(onRemote...); err != nil {
+		return nil, err
+	}
+	return onRemote, nil
+}
+
+// worktreeExtras are the optional steps run in a worktree once it is created
+type worktreeExtras struct {
+	Submodules    bool
+	IncludeSource string // Worktree to bring included local files from; "" when there is none
+	IncludeMode   include.Mode
+	MainWorktree  string // The default branch's worktree, see mainWorktree
+}
+
+// addWorktreeExtrasFlags registers the flags read by loadWorktreeExtras
+func addWorktreeExtrasFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("submodules", false, "Initialise submodules in the new worktree (default from gwtm.submodules)")
+	cmd.Flags().String("from", "", "Worktree to bring included local files from (default: the default branch's worktree)")
+	cmd.Flags().String("include-mode", "", "How included local files are brought in: copy, symlink or reflink (default from gwtm.includeMode, else copy)")
+}
+
+// loadWorktreeExtras resolves the extras for a new worktree at worktreePath
+// from cmd's flags and the project's settings
+func loadWorktreeExtras(cmd *cobra.Command, root, worktreePath string) (worktreeExtras, error) {
+	settings := loadSettings(root)
+	applyFlags(cmd, settings, map[string]string{"submodules": "submodules", "include-mode": "includeMode"})
+	extras := worktreeExtras{Submodules: settings.Bool("submodules")}
+
+	mode, err := include.ParseMode(settings.Get("includeMode"))
+	if err != nil {
+		return extras, withGuidance(err, "Set --include-mode or gwtm.includeMode to copy, symlink or reflink")
+	}
+	extras.IncludeMode = mode
+
+	extras.MainWorktree = mainWorktree(root, worktreePath)
+	extras.IncludeSource = extras.MainWorktree
+	if from, _ := cmd.Flags().GetString("from"); from != "" {
+		extras.IncludeSource = filepath.Join(root, worktreeDirName(from))
+		if _, err := os.Stat(extras.IncludeSource); err != nil {
+			return extras, withGuidance(fmt.Errorf("no worktree named %q", from), "Use 'gwtm list' to see available worktrees")
+		}
+	}
+
+	return extras, nil
+}
+
+// printDryRun describes what finishWorktree would do
+func (e worktreeExtras) printDryRun(root, worktreePath string) {
+	if e.Submodules {
+		ui.PrintDryRun("Would initialise submodules in the new worktree")
+	}
+	if patterns, _ := includePatterns(root, e.IncludeSource); e.IncludeSource != "" && len(patterns) > 0 {
+		ui.PrintDryRun(fmt.Sprintf("Would bring local files matching include patterns from %s (%s)", e.IncludeSource, e.IncludeMode))
+	}
+	if hasHooks(hooks.PostCreate, root, e.MainWorktree) {
+		ui.PrintDryRun("Would run " + hooks.PostCreate + " hooks in the new worktree")
+	}
+}
+
+// finishWorktree runs the extras and post-create hooks in a newly created
+// worktree. The worktree is usable even if one of them fails, so failures are
+// reported and the rest carry on.
+func finishWorktree(root, worktreePath, branch, baseBranch string, extras worktreeExtras) {
+	if extras.Submodules {
+		if err := initSubmodules(worktreePath, submoduleShareSource(root, worktreePath), ui.PrintStatus); err != nil {
+			printGuidedError(err, "Initialise submodules manually with 'git submodule update --init --recursive'")
+		}
+	}
+
+	if extras.IncludeSource != "" {
+		includeFiles(root, extras.IncludeSource, worktreePath, extras.IncludeMode, ui.PrintStatus)
+	}
+
+	env := hooks.Env{Branch: branch, WorktreePath: worktreePath, ProjectRoot: root, BaseBranch: baseBranch}
+	if err := runHooks(hooks.PostCreate, worktreePath, worktreePath, env, ui.PrintStatus); err != nil {
+		ui.PrintError(err, "Fix the hook and rerun it manually in "+worktreePath)
+	}
+}
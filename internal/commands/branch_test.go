@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/spf13/cobra"
+)
+
+func TestRunBranch_NoFetchStaysOffline(t *testing.T) {
+	root, _ := setupTestProject(t)
+	client := git.NewClient(root)
+
+	// Without origin/HEAD, finding the default branch over the network would
+	// ask origin, which here records any attempt to reach it
+	client.ExecGit("symbolic-ref", "--delete", "refs/remotes/origin/HEAD")
+	client.SetConfig("remote.origin.url", "ssh://gwtm.invalid/repo.git")
+	marker := filepath.Join(t.TempDir(), "contacted")
+	ssh := filepath.Join(t.TempDir(), "ssh")
+	os.WriteFile(ssh, []byte("#!/bin/sh\ntouch "+marker+"\nexit 1\n"), 0755)
+	t.Setenv("GIT_SSH_COMMAND", ssh)
+
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir) //nolint:errcheck
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().Bool("no-fetch", false, "")
+	cmd.Flags().Bool("no-push", false, "")
+	addWorktreeExtrasFlags(cmd)
+	cmd.Flags().Set("no-fetch", "true")
+	cmd.Flags().Set("no-push", "true")
+
+	runBranch(cmd, []string{"feature-offline"})
+
+	if _, err := os.Stat(filepath.Join(root, "feature-offline", "README.md")); err != nil {
+		t.Errorf("runBranch() with --no-fetch did not create the worktree: %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("runBranch() with --no-fetch contacted origin")
+	}
+}
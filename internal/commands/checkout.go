@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var checkoutCmd = &cobra.Command{
+	Use:   "checkout <tag|commit>",
+	Short: "Create a detached worktree for a tag or commit",
+	Long: `Create a worktree with HEAD detached at a tag or commit, e.g. to inspect a release
+or bisect a regression. No branch is created, so 'gwtm remove' only removes the directory.
+
+The worktree directory is named after the tag or commit unless --name is given.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runCheckout,
+}
+
+func init() {
+	checkoutCmd.Flags().String("name", "", "Directory name for the worktree (default: the tag or commit)")
+	addWorktreeExtrasFlags(checkoutCmd)
+	rootCmd.AddCommand(checkoutCmd)
+}
+
+func runCheckout(cmd *cobra.Command, args []string) {
+	rev := args[0]
+
+	root, err := findWorktreeRoot()
+	if err != nil {
+		ui.PrintError(err, "Run this command from within a worktree-managed repository")
+		return
+	}
+
+	name, _ := cmd.Flags().GetString("name")
+	if name == "" {
+		name = detachedWorktreeName(rev)
+	}
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." || name == ".bare" {
+		ui.PrintError(fmt.Errorf("invalid worktree name %q", name), "Pass a plain directory name with --name")
+		return
+	}
+
+	worktreePath := filepath.Join(root, name)
+	if _, err := os.Stat(worktreePath); err == nil {
+		ui.PrintError(fmt.Errorf("%s already exists", worktreePath), "Choose another directory with --name")
+		return
+	}
+
+	extras, err := loadWorktreeExtras(cmd, root, worktreePath)
+	if err != nil {
+		printGuidedError(err, "Check the checkout flags")
+		return
+	}
+
+	client := git.NewClient(root)
+	client.DryRun = GetDryRun()
+
+	if client.DryRun {
+		ui.PrintDryRun("Would fetch latest from origin")
+		ui.PrintDryRun(fmt.Sprintf("Would create worktree '%s' detached at '%s'", name, rev))
+		extras.printDryRun(root, worktreePath)
+		return
+	}
+
+	ui.PrintStatus("📡", "Fetching latest from origin")
+	if err := client.Fetch(true, false); err != nil {
+		ui.PrintError(err, "Check network connection")
+		return
+	}
+
+	commit, err := client.ResolveCommit(rev)
+	if err != nil {
+		ui.PrintError(err, "Check the name with 'git tag' or 'git log'")
+		return
+	}
+
+	ui.PrintStatus("📌", fmt.Sprintf("Creating worktree '%s' detached at %s (%s)", name, rev, commit[:12]))
+	if err := client.WorktreeAddDetached(worktreePath, commit); err != nil {
+		ui.PrintError(err, "Failed to create worktree")
+		return
+	}
+
+	finishWorktree(root, worktreePath, "", "", extras)
+
+	ui.PrintStatus("✅", "Worktree for '"+rev+"' is ready at: "+worktreePath)
+}
+
+// detachedWorktreeName derives a directory name from a tag or commit,
+// replacing characters that would create nested directories
+func detachedWorktreeName(rev string) string {
+	return strings.NewReplacer("/", "-", `\`, "-", "~", "-", "^", "-", ":", "-").Replace(rev)
+}
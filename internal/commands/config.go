@@ -0,0 +1,317 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and change gwtm settings",
+	Long: `Read and change gwtm settings. Each setting is taken from the first of these
+that sets it:
+
+  1. command-line flags
+  2. GWTM_* environment variables, e.g. GWTM_SYNC_MODE=rebase
+  3. gwtm.* in the project's git config (.bare/config)
+  4. the project's .gwtm.toml
+  5. gwtm.* in global and system git config
+  6. the user's config.toml, in ~/.config/gwtm on Linux and
+     ~/.git-worktree-manager elsewhere
+  7. built-in defaults
+
+Keys may be given with or without the gwtm. prefix. --show-origin shows which
+layer, and which file, variable or flag, each value came from.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Long:  "Print the value of a setting; list settings print one value per line.",
+	Args:  cobra.ExactArgs(1),
+	Run:   runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>...",
+	Short: "Change a setting in the user's or the project's settings file",
+	Long: `Change a setting in the user's config.toml, or with --project in the project's
+.gwtm.toml. List settings such as branch.prefix take several values. The rest
+of the file, comments included, is left as it was.`,
+	Args: cobra.MinimumNArgs(2),
+	Run:  runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting from the user's or the project's settings file",
+	Args:  cobra.ExactArgs(1),
+	Run:   runConfigUnset,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print every setting that has a value",
+	Args:  cobra.NoArgs,
+	Run:   runConfigList,
+}
+
+var configApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply the project's profile of git settings again",
+	Long: `Bring the project's git settings in line with its profile and show what
+changed. The profile is the one chosen with 'gwtm setup --profile', otherwise
+the profile setting, otherwise the default profile.
+
+Profiles are defined as profiles.<name>.<git key> settings and start from
+gwtm's built-in settings (push.default=current, branch.autosetupmerge=always,
+branch.autosetuprebase=always); an empty value leaves that key alone. Keys a
+profile no longer sets are not removed.
+
+  [profiles.norebase]
+  branch.autosetuprebase = "never"
+
+Use --dry-run to preview the changes.
+With --all, every project in the registry is updated.`,
+	Args: cobra.NoArgs,
+	Run:  runConfigApply,
+}
+
+func init() {
+	configGetCmd.Flags().Bool("show-origin", false, "Show where the value came from")
+	configListCmd.Flags().Bool("show-origin", false, "Show where each value came from")
+	configSetCmd.Flags().Bool("project", false, "Change the project's .gwtm.toml instead of the user's config.toml")
+	configUnsetCmd.Flags().Bool("project", false, "Change the project's .gwtm.toml instead of the user's config.toml")
+	configApplyCmd.Flags().Bool("all", false, "Apply to every project in the registry")
+	configCmd.AddCommand(configGetCmd, configSetCmd, configUnsetCmd, configListCmd, configApplyCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// currentSettings loads the settings that apply in the current directory
+func currentSettings() *config.Settings {
+	cwd, _ := os.Getwd()
+	return loadSettings(cwd)
+}
+
+// formatSetting returns the lines printed for v: one per value, as key=value
+// when withKey is set, prefixed by the origin and a tab when showOrigin is set
+func formatSetting(v config.Value, withKey, showOrigin bool) []string {
+	lines := make([]string, 0, len(v.Values))
+	for _, value := range v.Values {
+		line := value
+		if withKey {
+			line = v.Key + "=" + value
+		}
+		if showOrigin {
+			line = v.Origin.String() + "\t" + line
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) {
+	showOrigin, _ := cmd.Flags().GetBool("show-origin")
+
+	key := config.CanonicalKey(args[0])
+	if _, ok := config.LookupKey(key); !ok {
+		ui.PrintError(fmt.Errorf("unknown setting %q", args[0]), "Run 'gwtm config list' or see 'gwtm config --help'")
+		return
+	}
+
+	v, ok := currentSettings().Lookup(key)
+	if !ok {
+		ui.PrintError(fmt.Errorf("%s is not set", key), "Set it with 'gwtm config set "+key+" <value>'")
+		return
+	}
+	for _, line := range formatSetting(v, false, showOrigin) {
+		fmt.Println(line)
+	}
+}
+
+func runConfigList(cmd *cobra.Command, args []string) {
+	showOrigin, _ := cmd.Flags().GetBool("show-origin")
+	for _, v := range currentSettings().List() {
+		for _, line := range formatSetting(v, true, showOrigin) {
+			fmt.Println(line)
+		}
+	}
+}
+
+// fileLayer returns the layer of the file set and unset change
+func fileLayer(project bool) config.Layer {
+	if project {
+		return config.LayerProject
+	}
+	return config.LayerUser
+}
+
+// settingsFile returns the settings file set and unset change: the project's
+// .gwtm.toml when project is set, otherwise the user's config.toml
+func settingsFile(project bool) (string, error) {
+	if !project {
+		return config.GetConfigPath(), nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	root := settingsRoot(cwd)
+	if root == "" {
+		return "", fmt.Errorf("not in a worktree-managed repository")
+	}
+	return filepath.Join(root, config.ProjectConfigFile), nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) {
+	project, _ := cmd.Flags().GetBool("project")
+	path, err := settingsFile(project)
+	if err != nil {
+		ui.PrintError(err, "Run this from within a project, or leave out --project")
+		return
+	}
+
+	key, values := args[0], args[1:]
+	canonical, err := config.Validate(key, values)
+	if err != nil {
+		ui.PrintError(err, "See 'gwtm config --help' for the settings and their values")
+		return
+	}
+
+	if GetDryRun() {
+		ui.PrintDryRun(fmt.Sprintf("Would set %s = %s in %s", canonical, strings.Join(values, ","), path))
+		return
+	}
+	if err := config.SetInFile(path, canonical, values); err != nil {
+		ui.PrintError(err, "Check that "+path+" is writable and valid TOML")
+		return
+	}
+	ui.PrintStatus("✅", fmt.Sprintf("Set %s = %s in %s", canonical, strings.Join(values, ","), path))
+	warnIfOverridden(canonical, fileLayer(project))
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) {
+	project, _ := cmd.Flags().GetBool("project")
+	path, err := settingsFile(project)
+	if err != nil {
+		ui.PrintError(err, "Run this from within a project, or leave out --project")
+		return
+	}
+	key := config.CanonicalKey(args[0])
+
+	if GetDryRun() {
+		ui.PrintDryRun(fmt.Sprintf("Would remove %s from %s", key, path))
+		return
+	}
+	removed, err := config.UnsetInFile(path, key)
+	if err != nil {
+		ui.PrintError(err, "Check that "+path+" is writable and valid TOML")
+		return
+	}
+	if !removed {
+		ui.PrintStatus("ℹ️", fmt.Sprintf("%s is not set in %s", key, path))
+		return
+	}
+	ui.PrintStatus("✅", fmt.Sprintf("Removed %s from %s", key, path))
+	warnIfOverridden(key, fileLayer(project))
+}
+
+// warnIfOverridden points out when a layer above the one just changed decides
+// key, so the change has no effect
+func warnIfOverridden(key string, changed config.Layer) {
+	v, ok := currentSettings().Lookup(key)
+	if !ok || slices.Index(config.Layers, v.Origin.Layer) <= slices.Index(config.Layers, changed) {
+		return
+	}
+	ui.PrintStatus("⚠️", fmt.Sprintf("%s is %s from %s, which takes precedence", key, v.String(), v.Origin))
+}
+
+func runConfigApply(cmd *cobra.Command, args []string) {
+	all, _ := cmd.Flags().GetBool("all")
+
+	var roots []string
+	if all {
+		projects, ok := loadProjects()
+		if !ok {
+			return
+		}
+		for _, p := range projects {
+			roots = append(roots, p.Path)
+		}
+	} else {
+		cwd, _ := os.Getwd()
+		root := settingsRoot(cwd)
+		if root == "" {
+			ui.PrintError(fmt.Errorf("not in a worktree-managed repository"), "Run this from within a project, or use --all")
+			return
+		}
+		roots = append(roots, root)
+	}
+
+	failed := 0
+	for _, root := range roots {
+		if err := applyProfile(root); err != nil {
+			printGuidedError(fmt.Errorf("%s: %w", filepath.Base(root), err), "Fix the problem and run 'gwtm config apply' again")
+			failed++
+		}
+	}
+	if all && failed > 0 {
+		ui.PrintStatus("📊", fmt.Sprintf("Applied profiles to %d of %d projects", len(roots)-failed, len(roots)))
+	}
+}
+
+// applyProfile brings the git settings of the project at root in line with
+// its profile and prints the changes as a diff
+func applyProfile(root string) error {
+	if info, err := os.Stat(filepath.Join(root, ".bare")); err != nil || !info.IsDir() {
+		return withGuidance(fmt.Errorf("%s is not a worktree-managed project", root), "Run 'gwtm doctor --fix' to forget missing projects")
+	}
+
+	name, profile, err := gitProfile(loadSettings(root), "")
+	if err != nil {
+		return err
+	}
+
+	client := git.NewClient(filepath.Join(root, ".bare"))
+	client.DryRun = GetDryRun()
+	changes, err := client.ApplyConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	project := filepath.Base(root)
+	if len(changes) == 0 {
+		ui.PrintStatus("✅", fmt.Sprintf("%s already matches the %s profile", project, name))
+		return nil
+	}
+	if client.DryRun {
+		ui.PrintDryRun(fmt.Sprintf("Would apply the %s profile to %s:", name, project))
+	} else {
+		ui.PrintStatus("⚙️", fmt.Sprintf("Applied the %s profile to %s:", name, project))
+	}
+	for _, line := range formatConfigChanges(changes) {
+		fmt.Println("  " + line)
+	}
+	return nil
+}
+
+// formatConfigChanges returns changes as diff lines: the old value, when
+// there was one, prefixed with -, and the new value prefixed with +
+func formatConfigChanges(changes []git.ConfigChange) []string {
+	var lines []string
+	for _, change := range changes {
+		if change.Old != "" {
+			lines = append(lines, "- "+change.Key+"="+change.Old)
+		}
+		lines = append(lines, "+ "+change.Key+"="+change.New)
+	}
+	return lines
+}
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+)
+
+func TestFormatSetting(t *testing.T) {
+	v := config.Value{
+		Key:    "branch.prefix",
+		Values: []string{"feat/", "fix/"},
+		Origin: config.Origin{Layer: config.LayerProject, Source: "/p/.gwtm.toml"},
+	}
+
+	tests := []struct {
+		name       string
+		withKey    bool
+		showOrigin bool
+		want       []string
+	}{
+		{"values", false, false, []string{"feat/", "fix/"}},
+		{"keys", true, false, []string{"branch.prefix=feat/", "branch.prefix=fix/"}},
+		{"origin", true, true, []string{"project:/p/.gwtm.toml\tbranch.prefix=feat/", "project:/p/.gwtm.toml\tbranch.prefix=fix/"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatSetting(v, tt.withKey, tt.showOrigin)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("formatSetting() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettingsRoot(t *testing.T) {
+	root, _ := setupTestProject(t)
+	worktree := filepath.Join(root, "feature")
+	if err := os.MkdirAll(filepath.Join(worktree, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{root, worktree, filepath.Join(worktree, "src")} {
+		if got := settingsRoot(dir); got != root {
+			t.Errorf("settingsRoot(%q) = %q, want %q", dir, got, root)
+		}
+	}
+	if got := settingsRoot(t.TempDir()); got != "" {
+		t.Errorf("settingsRoot() outside a project = %q, want empty", got)
+	}
+	if got := settingsRoot(""); got != "" {
+		t.Errorf("settingsRoot(\"\") = %q, want empty", got)
+	}
+}
+
+func TestLoadSettings_ProjectFile(t *testing.T) {
+	root, branch := setupTestProject(t)
+	os.WriteFile(filepath.Join(root, config.ProjectConfigFile), []byte("syncMode = \"rebase\"\n"), 0644)
+	t.Setenv("GWTM_SYNC_MODE", "")
+
+	// Worktrees share their project's settings
+	settings := loadSettings(filepath.Join(root, worktreeDirName(branch)))
+	rebase, err := syncRebase(settings)
+	if err != nil || !rebase {
+		t.Errorf("syncRebase() = %v, %v, want true from %s", rebase, err, config.ProjectConfigFile)
+	}
+
+	settings.SetFlag("syncMode", "--rebase", "ff")
+	if rebase, _ := syncRebase(settings); rebase {
+		t.Error("syncRebase() = true, want the flag to take precedence")
+	}
+}
+
+func TestFormatConfigChanges(t *testing.T) {
+	changes := []git.ConfigChange{
+		{Key: "branch.autosetuprebase", Old: "always", New: "never"},
+		{Key: "pull.rebase", New: "false"},
+	}
+	want := []string{
+		"- branch.autosetuprebase=always",
+		"+ branch.autosetuprebase=never",
+		"+ pull.rebase=false",
+	}
+	if got := formatConfigChanges(changes); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("formatConfigChanges() = %q, want %q", got, want)
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	root, _ := setupTestProject(t)
+	t.Setenv("GWTM_PROFILE", "")
+	bare := git.NewClient(filepath.Join(root, ".bare"))
+
+	os.WriteFile(filepath.Join(root, config.ProjectConfigFile), []byte(`profile = "norebase"
+
+[profiles.norebase]
+branch.autosetuprebase = "never"
+pull.rebase = "false"
+`), 0644)
+
+	if err := applyProfile(root); err != nil {
+		t.Fatalf("applyProfile() error = %v", err)
+	}
+	for key, want := range map[string]string{"branch.autosetuprebase": "never", "pull.rebase": "false", "push.default": "current"} {
+		if got, _ := bare.GetConfig(key); got != want {
+			t.Errorf("after applyProfile() %s = %q, want %q", key, got, want)
+		}
+	}
+	if changes, _ := bare.ConfigChanges(map[string]string{"branch.autosetuprebase": "never"}); len(changes) != 0 {
+		t.Errorf("ConfigChanges() after applyProfile() = %v, want none", changes)
+	}
+
+	os.WriteFile(filepath.Join(root, config.ProjectConfigFile), []byte("profile = \"missing\"\n"), 0644)
+	if err := applyProfile(root); err == nil || !strings.Contains(err.Error(), "unknown profile") {
+		t.Errorf("applyProfile() with an unknown profile error = %v", err)
+	}
+	if err := applyProfile(t.TempDir()); err == nil {
+		t.Error("applyProfile() outside a project error = nil, want error")
+	}
+}
@@ -0,0 +1,443 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/registry"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the health of a worktree-managed repository",
+	Long: `Check that the repository still satisfies everything 'gwtm setup' establishes:
+the .git file, the bare repository, fetch refspec, remote HEAD, the git
+settings of the project's profile, the links between the repository and its
+worktrees, and its entry in the project registry. Registry entries for
+projects that were moved or deleted are reported too.
+
+Use --fix to repair any problems that can be fixed automatically. The exit
+status is non-zero while any problem remains.`,
+	Run: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().Bool("fix", false, "Repair problems that can be fixed automatically")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctorCheck is a single invariant of a worktree-managed project
+type doctorCheck struct {
+	Name  string
+	Check func() error // Returns a description of the problem, or nil when healthy
+	Fix   func() error // Repairs the problem; nil when it cannot be fixed automatically
+	Hint  string       // Guidance shown when the problem cannot be fixed automatically
+}
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	fix, _ := cmd.Flags().GetBool("fix")
+
+	root, err := findProjectRoot()
+	if err != nil {
+		ui.PrintError(err, "Run this command from within a worktree-managed repository")
+		return
+	}
+
+	ui.PrintStatus("🩺", "Checking "+root)
+
+	var problems, fixed int
+	for _, check := range doctorChecks(root) {
+		checkErr := check.Check()
+		if checkErr == nil {
+			ui.PrintStatus("✅", check.Name)
+			continue
+		}
+
+		problems++
+
+		// A problem that stays is an error, so scripts see a non-zero exit status
+		if !fix || check.Fix == nil || GetDryRun() {
+			hint := check.Hint
+			if check.Fix != nil {
+				hint = "Run 'gwtm doctor --fix' to repair it"
+			}
+			ui.PrintError(fmt.Errorf("%s: %w", check.Name, checkErr), hint)
+			if fix && check.Fix != nil {
+				ui.PrintDryRun("Would fix: " + check.Name)
+			}
+			continue
+		}
+
+		ui.PrintStatus("❌", fmt.Sprintf("%s: %v", check.Name, checkErr))
+		if err := check.Fix(); err != nil {
+			ui.PrintError(err, "Fix this problem manually")
+			continue
+		}
+		if err := check.Check(); err != nil {
+			ui.PrintError(err, "The fix did not resolve the problem — fix it manually")
+			continue
+		}
+		fixed++
+		ui.PrintStatus("🔧", "Fixed: "+check.Name)
+	}
+
+	switch {
+	case problems == 0:
+		ui.PrintStatus("✅", "No problems found.")
+	case fixed == problems:
+		ui.PrintStatus("✅", fmt.Sprintf("Fixed %d problem(s).", fixed))
+	case fix:
+		ui.PrintStatus("⚠️", fmt.Sprintf("Found %d problem(s), fixed %d.", problems, fixed))
+	default:
+		ui.PrintStatus("⚠️", fmt.Sprintf("Found %d problem(s). Run 'gwtm doctor --fix' to repair them.", problems))
+	}
+}
+
+// findProjectRoot locates the project root like findWorktreeRoot, but also
+// accepts a directory containing .bare so that a missing .git file can be diagnosed.
+func findProjectRoot() (string, error) {
+	if root, err := findWorktreeRoot(); err == nil {
+		return root, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	if info, err := os.Stat(filepath.Join(cwd, ".bare")); err == nil && info.IsDir() {
+		return cwd, nil
+	}
+
+	return "", fmt.Errorf("not in a worktree-managed repository")
+}
+
+// doctorChecks returns the checks for the project at root, in the order they
+// should run. Every git command runs against .bare directly so the checks still
+// work when the root .git file is broken.
+func doctorChecks(root string) []doctorCheck {
+	bareDir := filepath.Join(root, ".bare")
+	bare := git.NewClient(bareDir)
+	bare.DryRun = GetDryRun()
+
+	return []doctorCheck{
+		{
+			Name: ".bare is a bare repository",
+			Check: func() error {
+				stdout, _, err := git.NewClient(bareDir).ExecGit("rev-parse", "--is-bare-repository")
+				if err != nil || strings.TrimSpace(stdout) != "true" {
+					return fmt.Errorf("%s is missing or not a bare repository", bareDir)
+				}
+				return nil
+			},
+			Hint: "Re-create the project with 'gwtm setup'",
+		},
+		{
+			Name: "borrowed objects are available",
+			Check: func() error {
+				missing, err := missingAlternates(bareDir)
+				if err != nil {
+					return err
+				}
+				if len(missing) > 0 {
+					return fmt.Errorf("object stores listed in alternates no longer exist: %s", strings.Join(missing, ", "))
+				}
+				return nil
+			},
+			Hint: "Restore the reference repository or cache it was cloned with, then run 'git repack -a -d' in .bare to stop depending on it",
+		},
+		{
+			Name: ".git file points to .bare",
+			Check: func() error {
+				target, err := readGitdirFile(root)
+				if err != nil {
+					return err
+				}
+				if !samePath(target, bareDir) {
+					return fmt.Errorf(".git points to %s", target)
+				}
+				return nil
+			},
+			Fix: func() error {
+				return os.WriteFile(filepath.Join(root, ".git"), []byte("gitdir: ./.bare"), 0644)
+			},
+		},
+		{
+			Name: "origin remote is configured",
+			Check: func() error {
+				url, err := git.NewClient(bareDir).GetConfig("remote.origin.url")
+				if err != nil {
+					return err
+				}
+				if url == "" {
+					return fmt.Errorf("remote.origin.url is not set")
+				}
+				return nil
+			},
+			Hint: "Add the remote with 'git remote add origin <url>'",
+		},
+		{
+			Name: "fetch refspec includes all remote branches",
+			Check: func() error {
+				refspecs, err := git.NewClient(bareDir).GetConfigAll("remote.origin.fetch")
+				if err != nil {
+					return err
+				}
+				if !slices.Contains(refspecs, git.FetchRefspec) {
+					return fmt.Errorf("remote.origin.fetch does not include %s", git.FetchRefspec)
+				}
+				return nil
+			},
+			Fix: bare.ConfigureFetchRefspec,
+		},
+		{
+			Name: "git settings for worktree management",
+			Check: func() error {
+				name, profile, err := gitProfile(loadSettings(root), "")
+				if err != nil {
+					return err
+				}
+				changes, err := bare.ConfigChanges(profile)
+				if err != nil {
+					return err
+				}
+				var wrong []string
+				for _, change := range changes {
+					wrong = append(wrong, fmt.Sprintf("%s=%q (want %q)", change.Key, change.Old, change.New))
+				}
+				if len(wrong) > 0 {
+					return fmt.Errorf("%s profile: %s", name, strings.Join(wrong, ", "))
+				}
+				return nil
+			},
+			Fix: func() error {
+				_, profile, err := gitProfile(loadSettings(root), "")
+				if err != nil {
+					return err
+				}
+				_, err = bare.ApplyConfig(profile)
+				return err
+			},
+		},
+		{
+			Name: "remote HEAD is known",
+			Check: func() error {
+				if !git.NewClient(bareDir).RefExists("refs/remotes/origin/HEAD") {
+					return fmt.Errorf("refs/remotes/origin/HEAD is missing")
+				}
+				return nil
+			},
+			Fix: bare.SetRemoteHead,
+		},
+		// Repair must run before pruning: after the project is moved, the
+		// admin entries of moved worktrees look stale until they are repaired
+		{
+			Name: "worktree directories are registered",
+			Check: func() error {
+				unregistered, err := unregisteredWorktreeDirs(root)
+				if err != nil {
+					return err
+				}
+				if len(unregistered) > 0 {
+					return fmt.Errorf("not registered with git: %s", strings.Join(unregistered, ", "))
+				}
+				return nil
+			},
+			Fix: func() error {
+				unregistered, err := unregisteredWorktreeDirs(root)
+				if err != nil {
+					return err
+				}
+				return bare.WorktreeRepair(bare.SupportsRelativeWorktreePaths(), unregistered...)
+			},
+		},
+		{
+			Name: "registered worktrees exist",
+			Check: func() error {
+				worktrees, err := git.NewClient(bareDir).WorktreeListPorcelain()
+				if err != nil {
+					return err
+				}
+				var missing []string
+				for _, wt := range worktrees {
+					if wt.Prunable {
+						missing = append(missing, wt.Path)
+					}
+				}
+				if len(missing) > 0 {
+					return fmt.Errorf("directories no longer exist: %s", strings.Join(missing, ", "))
+				}
+				return nil
+			},
+			Fix: bare.WorktreePrune,
+		},
+		{
+			Name: "project is in the registry",
+			Check: func() error {
+				reg, err := registry.Load(config.GetRegistryPath())
+				if err != nil {
+					return err
+				}
+				if !slices.ContainsFunc(reg.Projects, func(p registry.Project) bool { return samePath(p.Path, root) }) {
+					return fmt.Errorf("%s is not recorded in %s", root, config.GetRegistryPath())
+				}
+				return nil
+			},
+			Fix: func() error {
+				return registerProject(root)
+			},
+		},
+		{
+			Name: "registered projects exist",
+			Check: func() error {
+				missing, err := missingProjects()
+				if err != nil {
+					return err
+				}
+				if len(missing) > 0 {
+					return fmt.Errorf("projects no longer exist: %s", strings.Join(missing, ", "))
+				}
+				return nil
+			},
+			Fix: func() error {
+				missing, err := missingProjects()
+				if err != nil {
+					return err
+				}
+				return registry.Update(config.GetRegistryPath(), func(r *registry.Registry) {
+					for _, path := range missing {
+						r.Remove(path)
+					}
+				})
+			},
+		},
+	}
+}
+
+// missingProjects returns the registered project paths that no longer hold a
+// .bare repository, usually because the project was moved or deleted
+func missingProjects() ([]string, error) {
+	reg, err := registry.Load(config.GetRegistryPath())
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, p := range reg.Projects {
+		if info, err := os.Stat(filepath.Join(p.Path, ".bare")); err != nil || !info.IsDir() {
+			missing = append(missing, p.Path)
+		}
+	}
+	return missing, nil
+}
+
+// worktreeDirs returns the immediate subdirectories of root that contain a
+// .git file, i.e. directories that look like linked worktrees.
+func worktreeDirs(root string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == ".bare" {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		if info, err := os.Stat(filepath.Join(dir, ".git")); err == nil && !info.IsDir() {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs, nil
+}
+
+// unregisteredWorktreeDirs returns worktree-like directories under root that
+// git does not list as worktrees.
+func unregisteredWorktreeDirs(root string) ([]string, error) {
+	dirs, err := worktreeDirs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees, err := git.NewClient(filepath.Join(root, ".bare")).WorktreeListPorcelain()
+	if err != nil {
+		return nil, err
+	}
+
+	var unregistered []string
+	for _, dir := range dirs {
+		registered := slices.ContainsFunc(worktrees, func(wt git.Worktree) bool {
+			return !wt.Prunable && samePath(wt.Path, dir)
+		})
+		if !registered {
+			unregistered = append(unregistered, dir)
+		}
+	}
+
+	return unregistered, nil
+}
+
+// missingAlternates returns the object directories listed in
+// .bare/objects/info/alternates that no longer exist
+func missingAlternates(bareDir string) ([]string, error) {
+	objectsDir := filepath.Join(bareDir, "objects")
+	data, err := os.ReadFile(filepath.Join(objectsDir, "info", "alternates"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alternates: %w", err)
+	}
+
+	var missing []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		path := line
+		if !filepath.IsAbs(path) {
+			// Relative entries are relative to the objects directory
+			path = filepath.Join(objectsDir, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			missing = append(missing, line)
+		}
+	}
+
+	return missing, nil
+}
+
+// readGitdirFile returns the absolute path that dir/.git points to
+func readGitdirFile(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ".git"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read .git file: %w", err)
+	}
+
+	target, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !found {
+		return "", fmt.Errorf("%s/.git is not a gitdir file", dir)
+	}
+
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	return filepath.Clean(target), nil
+}
+
+// samePath reports whether a and b refer to the same existing file or directory
+func samePath(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/registry"
+)
+
+// setupTestProject creates an upstream repository with one commit and sets it
+// up as a worktree-managed project. Returns the project root and default branch.
+func setupTestProject(t *testing.T) (string, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	// Keep the project registry out of the real install directory
+	t.Setenv("GIT_WORKTREE_MANAGER_HOME", filepath.Join(tmpDir, "home"))
+
+	srcDir := filepath.Join(tmpDir, "src")
+	os.MkdirAll(srcDir, 0755)
+	src := git.NewClient(srcDir)
+	src.ExecGit("init")
+	src.ExecGit("config", "user.name", "Test User")
+	src.ExecGit("config", "user.email", "test@example.com")
+	os.WriteFile(filepath.Join(srcDir, "README.md"), []byte("# Test\n"), 0644)
+	src.ExecGit("add", "README.md")
+	src.ExecGit("commit", "-m", "Initial commit")
+
+	upstreamDir := filepath.Join(tmpDir, "upstream.git")
+	src.ExecGit("clone", "--bare", srcDir, upstreamDir)
+
+	root := filepath.Join(tmpDir, "project")
+	branch, err := setupProject("file://"+upstreamDir, root, setupOptions{Settings: loadSettings("")}, func(string, string) {})
+	if err != nil {
+		t.Fatalf("setupProject() error = %v", err)
+	}
+
+	// Commits made inside the project need an identity too
+	git.NewClient(root).SetConfig("user.name", "Test User")
+	git.NewClient(root).SetConfig("user.email", "test@example.com")
+
+	return root, branch
+}
+
+// failingChecks returns the names of the doctor checks that currently fail
+func failingChecks(root string) []string {
+	var failing []string
+	for _, check := range doctorChecks(root) {
+		if check.Check() != nil {
+			failing = append(failing, check.Name)
+		}
+	}
+	return failing
+}
+
+func TestDoctorChecks_Healthy(t *testing.T) {
+	root, _ := setupTestProject(t)
+
+	if failing := failingChecks(root); len(failing) > 0 {
+		t.Errorf("doctorChecks() on a fresh project reported problems: %v", failing)
+	}
+}
+
+func TestDoctorChecks_DetectAndFix(t *testing.T) {
+	root, branch := setupTestProject(t)
+	bare := git.NewClient(filepath.Join(root, ".bare"))
+
+	// Break the invariants runSetup establishes
+	os.WriteFile(filepath.Join(root, ".git"), []byte("gitdir: ./elsewhere"), 0644)
+	bare.ExecGit("config", "--unset-all", "remote.origin.fetch")
+	bare.ExecGit("config", "--unset", "push.default")
+	bare.ExecGit("symbolic-ref", "--delete", "refs/remotes/origin/HEAD")
+	os.RemoveAll(filepath.Join(root, branch))
+	os.MkdirAll(filepath.Join(root, "stray"), 0755)
+	os.WriteFile(filepath.Join(root, "stray", ".git"), []byte("gitdir: /nowhere"), 0644)
+
+	failing := failingChecks(root)
+	want := []string{
+		".git file points to .bare",
+		"fetch refspec includes all remote branches",
+		"git settings for worktree management",
+		"remote HEAD is known",
+		"worktree directories are registered",
+		"registered worktrees exist",
+	}
+	if strings.Join(failing, "|") != strings.Join(want, "|") {
+		t.Fatalf("doctorChecks() failing = %v, want %v", failing, want)
+	}
+
+	// Unregistered directories that git cannot repair are reported but left alone
+	os.RemoveAll(filepath.Join(root, "stray"))
+
+	for _, check := range doctorChecks(root) {
+		if check.Check() != nil && check.Fix != nil {
+			if err := check.Fix(); err != nil {
+				t.Errorf("%s: Fix() error = %v", check.Name, err)
+			}
+		}
+	}
+
+	if failing := failingChecks(root); len(failing) > 0 {
+		t.Errorf("doctorChecks() after fixes still failing: %v", failing)
+	}
+}
+
+func TestDoctorChecks_Registry(t *testing.T) {
+	root, _ := setupTestProject(t)
+
+	// Forget this project and remember one that was deleted
+	gone := filepath.Join(filepath.Dir(root), "gone")
+	registry.Update(config.GetRegistryPath(), func(r *registry.Registry) {
+		r.Remove(root)
+		r.Add(registry.Project{Path: gone})
+	})
+
+	failing := failingChecks(root)
+	want := []string{"project is in the registry", "registered projects exist"}
+	if strings.Join(failing, "|") != strings.Join(want, "|") {
+		t.Fatalf("doctorChecks() failing = %v, want %v", failing, want)
+	}
+
+	for _, check := range doctorChecks(root) {
+		if check.Check() != nil {
+			if err := check.Fix(); err != nil {
+				t.Errorf("%s: Fix() error = %v", check.Name, err)
+			}
+		}
+	}
+
+	if failing := failingChecks(root); len(failing) > 0 {
+		t.Errorf("doctorChecks() after fixes still failing: %v", failing)
+	}
+}
@@ -0,0 +1,344 @@
+package commands
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var duCmd = &cobra.Command{
+	Use:   "du [worktree...]",
+	Short: "Show disk usage per worktree",
+	Long: `Show how much disk space each worktree uses, split into tracked files,
+ignored files (build outputs such as node_modules, target/ or .venv) and other
+untracked files, plus the object store in .bare that all worktrees share.
+
+Name worktrees to report on only those. --clean-ignored deletes the ignored
+files in the reported worktrees with 'git clean -fdX' after asking.`,
+	Run: runDu,
+}
+
+func init() {
+	duCmd.Flags().String("sort", "size", "Sort by size, ignored or name")
+	duCmd.Flags().Bool("json", false, "Print the report as JSON")
+	duCmd.Flags().Bool("clean-ignored", false, "Delete ignored files (build outputs) in the reported worktrees")
+	rootCmd.AddCommand(duCmd)
+}
+
+// worktreeUsage is the disk usage of one worktree, in bytes
+type worktreeUsage struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Branch    string `json:"branch,omitempty"`
+	Tracked   int64  `json:"tracked"`
+	Ignored   int64  `json:"ignored"`
+	Untracked int64  `json:"untracked"`
+	Total     int64  `json:"total"`
+}
+
+// diskUsage is the disk usage of a whole project, in bytes
+type diskUsage struct {
+	Worktrees []worktreeUsage `json:"worktrees"`
+	Bare      int64           `json:"bare"`
+	Total     int64           `json:"total"`
+}
+
+func runDu(cmd *cobra.Command, args []string) {
+	sortBy, _ := cmd.Flags().GetString("sort")
+	if !slices.Contains([]string{"size", "ignored", "name"}, sortBy) {
+		ui.PrintError(fmt.Errorf("unknown sort order %q", sortBy), "Use --sort size, ignored or name")
+		return
+	}
+	asJSON, _ := cmd.Flags().GetBool("json")
+	clean, _ := cmd.Flags().GetBool("clean-ignored")
+
+	root, err := findWorktreeRoot()
+	if err != nil {
+		ui.PrintError(err, "Run this command from within a worktree-managed repository")
+		return
+	}
+
+	worktrees, err := selectWorktrees(root, args)
+	if err != nil {
+		ui.PrintError(err, "Use 'gwtm list' to see available worktrees")
+		return
+	}
+
+	usage, err := measureProject(root, worktrees)
+	if err != nil {
+		ui.PrintError(err, "Failed to measure disk usage")
+		return
+	}
+	sortUsage(usage.Worktrees, sortBy)
+
+	if asJSON {
+		out, _ := json.MarshalIndent(usage, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		printDiskUsage(usage)
+	}
+
+	if clean {
+		cleanIgnored(root, usage.Worktrees)
+	}
+}
+
+// selectWorktrees returns the project's worktrees, or only the named ones
+// (branch or directory names) when names are given
+func selectWorktrees(root string, names []string) ([]git.Worktree, error) {
+	all, err := git.NewClient(root).WorktreeListPorcelain()
+	if err != nil {
+		return nil, err
+	}
+
+	var worktrees []git.Worktree
+	for _, wt := range all {
+		if !wt.Bare && !wt.Prunable {
+			worktrees = append(worktrees, wt)
+		}
+	}
+	if len(names) == 0 {
+		return worktrees, nil
+	}
+
+	var selected []git.Worktree
+	for _, name := range names {
+		path := filepath.Join(root, worktreeDirName(name))
+		i := slices.IndexFunc(worktrees, func(wt git.Worktree) bool { return samePath(wt.Path, path) })
+		if i < 0 {
+			return nil, fmt.Errorf("no worktree named %q", name)
+		}
+		selected = append(selected, worktrees[i])
+	}
+	return selected, nil
+}
+
+// measureProject measures each worktree, in parallel, and the shared .bare
+// object store
+func measureProject(root string, worktrees []git.Worktree) (diskUsage, error) {
+	usage := diskUsage{Worktrees: make([]worktreeUsage, len(worktrees))}
+	errs := make([]error, len(worktrees))
+	var wg sync.WaitGroup
+
+	for i, wt := range worktrees {
+		wg.Add(1)
+		go func(i int, wt git.Worktree) {
+			defer wg.Done()
+			usage.Worktrees[i], errs[i] = measureWorktree(root, wt)
+		}(i, wt)
+	}
+
+	bare, err := dirSize(filepath.Join(root, ".bare"))
+	wg.Wait()
+	if err != nil {
+		return usage, err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return usage, err
+		}
+	}
+
+	usage.Bare = bare
+	usage.Total = bare
+	for _, wt := range usage.Worktrees {
+		usage.Total += wt.Total
+	}
+	return usage, nil
+}
+
+// measureWorktree adds up the sizes of the files in wt by kind
+func measureWorktree(root string, wt git.Worktree) (worktreeUsage, error) {
+	usage := worktreeUsage{Name: worktreeName(root, wt.Path), Path: wt.Path, Branch: wt.Branch}
+	client := git.NewClient(wt.Path)
+
+	tracked, err := client.TrackedFiles()
+	if err != nil {
+		return usage, err
+	}
+	ignored, err := client.IgnoredPaths()
+	if err != nil {
+		return usage, err
+	}
+
+	isTracked := make(map[string]bool, len(tracked))
+	for _, file := range tracked {
+		isTracked[file] = true
+	}
+	isIgnored := make(map[string]bool, len(ignored))
+	for _, path := range ignored {
+		isIgnored[path] = true
+		size, err := dirSize(filepath.Join(wt.Path, strings.TrimSuffix(path, "/")))
+		if err != nil {
+			return usage, err
+		}
+		usage.Ignored += size
+	}
+
+	err = filepath.WalkDir(wt.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(wt.Path, path)
+		rel = filepath.ToSlash(rel)
+		if rel == ".git" {
+			return nil
+		}
+		if d.IsDir() {
+			// Ignored directories were measured as a whole above
+			if isIgnored[rel+"/"] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isIgnored[rel] {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if isTracked[rel] {
+			usage.Tracked += info.Size()
+		} else {
+			usage.Untracked += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return usage, fmt.Errorf("failed to measure %s: %w", wt.Path, err)
+	}
+
+	usage.Total = usage.Tracked + usage.Ignored + usage.Untracked
+	return usage, nil
+}
+
+// dirSize returns the total size of the files under path, which may also be
+// a single file. Symlinks count as themselves, not what they point to.
+func dirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to measure %s: %w", path, err)
+	}
+	return size, nil
+}
+
+// sortUsage orders worktrees by sortBy: size and ignored put the largest
+// first, name sorts alphabetically
+func sortUsage(worktrees []worktreeUsage, sortBy string) {
+	slices.SortStableFunc(worktrees, func(a, b worktreeUsage) int {
+		switch sortBy {
+		case "size":
+			return cmp.Or(cmp.Compare(b.Total, a.Total), strings.Compare(a.Name, b.Name))
+		case "ignored":
+			return cmp.Or(cmp.Compare(b.Ignored, a.Ignored), strings.Compare(a.Name, b.Name))
+		default:
+			return strings.Compare(a.Name, b.Name)
+		}
+	})
+}
+
+// printDiskUsage prints the report as a table
+func printDiskUsage(usage diskUsage) {
+	width := len("worktree")
+	for _, wt := range usage.Worktrees {
+		width = max(width, len(wt.Name))
+	}
+
+	ui.PrintStatus("💾", "Disk usage:")
+	row := func(name, tracked, ignored, untracked, total string) {
+		fmt.Printf("  %-*s  %10s  %10s  %10s  %10s\n", width, name, tracked, ignored, untracked, total)
+	}
+	row("worktree", "tracked", "ignored", "untracked", "total")
+	for _, wt := range usage.Worktrees {
+		row(wt.Name, formatBytes(wt.Tracked), formatBytes(wt.Ignored), formatBytes(wt.Untracked), formatBytes(wt.Total))
+	}
+	row(".bare", "", "", "", formatBytes(usage.Bare))
+	row("total", "", "", "", formatBytes(usage.Total))
+}
+
+// formatBytes renders n bytes with a binary unit, e.g. 1.5 MiB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// cleanIgnored deletes the ignored files in worktrees after confirming, or
+// only lists what it would delete in dry-run mode
+func cleanIgnored(root string, worktrees []worktreeUsage) {
+	var targets []worktreeUsage
+	var total int64
+	for _, wt := range worktrees {
+		if wt.Ignored > 0 {
+			targets = append(targets, wt)
+			total += wt.Ignored
+		}
+	}
+	if len(targets) == 0 {
+		ui.PrintStatus("✅", "No ignored files to clean")
+		return
+	}
+
+	if GetDryRun() {
+		for _, wt := range targets {
+			ui.PrintDryRun("Would delete ignored files in " + wt.Name + " (" + formatBytes(wt.Ignored) + ")")
+		}
+		return
+	}
+
+	question := fmt.Sprintf("Delete %s of ignored files in %d worktree(s)?", formatBytes(total), len(targets))
+	answer, err := confirm("🧹", question, nil)
+	if err != nil {
+		ui.PrintError(err, confirmGuidance("--yes"))
+		return
+	}
+	if !answer {
+		ui.PrintStatus("❌", "Cancelled")
+		return
+	}
+
+	var freed int64
+	for _, wt := range targets {
+		ui.PrintStatus("🧹", "Cleaning ignored files in "+wt.Name)
+		if err := git.NewClient(wt.Path).CleanIgnored(); err != nil {
+			ui.PrintError(err, "Check for files in use in "+wt.Path)
+			continue
+		}
+		freed += wt.Ignored
+	}
+
+	ui.PrintStatus("✅", "Freed "+formatBytes(freed))
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMeasureProject(t *testing.T) {
+	root, branch := setupTestProject(t)
+	path := filepath.Join(root, branch)
+
+	write := func(rel string, size int) {
+		t.Helper()
+		full := filepath.Join(path, rel)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		if err := os.WriteFile(full, []byte(strings.Repeat("x", size)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 34 bytes untracked, 1500 bytes in an ignored directory
+	os.WriteFile(filepath.Join(path, ".gitignore"), []byte("node_modules/\n"), 0644)
+	write("scratch.txt", 20)
+	write("node_modules/pkg/index.js", 1000)
+	write("node_modules/pkg/lib.js", 500)
+
+	worktrees, err := selectWorktrees(root, []string{branch})
+	if err != nil {
+		t.Fatalf("selectWorktrees() error = %v", err)
+	}
+	usage, err := measureProject(root, worktrees)
+	if err != nil {
+		t.Fatalf("measureProject() error = %v", err)
+	}
+
+	if len(usage.Worktrees) != 1 {
+		t.Fatalf("measureProject() measured %d worktrees, want 1", len(usage.Worktrees))
+	}
+	got := usage.Worktrees[0]
+	readme, _ := os.Stat(filepath.Join(path, "README.md"))
+	want := worktreeUsage{
+		Name:      branch,
+		Path:      got.Path,
+		Branch:    branch,
+		Tracked:   readme.Size(),
+		Ignored:   1500,
+		Untracked: 34,
+	}
+	want.Total = want.Tracked + want.Ignored + want.Untracked
+	if got != want {
+		t.Errorf("measureProject() worktree usage = %+v, want %+v", got, want)
+	}
+	if usage.Bare <= 0 || usage.Total != usage.Bare+got.Total {
+		t.Errorf("measureProject() bare = %d, total = %d, want bare > 0 and total = bare + worktrees", usage.Bare, usage.Total)
+	}
+
+	if err := git.NewClient(path).CleanIgnored(); err != nil {
+		t.Fatal(err)
+	}
+	usage, _ = measureProject(root, worktrees)
+	if usage.Worktrees[0].Ignored != 0 {
+		t.Errorf("after CleanIgnored() ignored = %d, want 0", usage.Worktrees[0].Ignored)
+	}
+}
+
+func TestSelectWorktrees_Unknown(t *testing.T) {
+	root, _ := setupTestProject(t)
+	if _, err := selectWorktrees(root, []string{"no-such-worktree"}); err == nil {
+		t.Error("selectWorktrees() with an unknown name error = nil, want error")
+	}
+}
+
+func TestCleanIgnored_DryRunDoesNotPrompt(t *testing.T) {
+	root, branch := setupTestProject(t)
+	path := filepath.Join(root, branch)
+	os.WriteFile(filepath.Join(path, ".gitignore"), []byte("build/\n"), 0644)
+	os.MkdirAll(filepath.Join(path, "build"), 0755)
+	os.WriteFile(filepath.Join(path, "build", "out.bin"), []byte("binary"), 0644)
+
+	dryRun = true
+	defer func() { dryRun = false }()
+
+	// Input isn't interactive under go test, so a prompt would fail
+	errorsBefore := ui.ErrorCount()
+	cleanIgnored(root, []worktreeUsage{{Name: branch, Path: path, Ignored: 6}})
+
+	if ui.ErrorCount() != errorsBefore {
+		t.Error("cleanIgnored() in dry-run mode asked for confirmation")
+	}
+	if _, err := os.Stat(filepath.Join(path, "build", "out.bin")); err != nil {
+		t.Errorf("cleanIgnored() in dry-run mode deleted an ignored file: %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec [--filter <glob>] [--parallel N] [--fail-fast] -- <command> [args...]",
+	Short: "Run a command in every worktree",
+	Long: `Run a command in each worktree directory and summarise the exit codes.
+
+The command is run directly, not through a shell — use sh -c '...' for pipes
+and other shell syntax. --filter selects worktrees whose directory name
+matches a glob (branch-style slashes are accepted, so feat/* matches feat-x).
+
+With --parallel 1 (the default) output is streamed as it is produced; with
+more, each worktree's output is collected and printed as one block when it
+finishes, so blocks are never interleaved.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runExec,
+}
+
+func init() {
+	execCmd.Flags().String("filter", "", "Only run in worktrees whose directory name matches this glob")
+	execCmd.Flags().Int("parallel", 1, "Number of worktrees to run the command in at once")
+	execCmd.Flags().Bool("fail-fast", false, "Stop starting the command in more worktrees after the first failure")
+	rootCmd.AddCommand(execCmd)
+}
+
+type execResult struct {
+	Name     string
+	Ran      bool
+	ExitCode int   // -1 when the command could not be started
+	Err      error // Why the command could not be started
+	Duration time.Duration
+}
+
+func runExec(cmd *cobra.Command, args []string) {
+	root, err := findWorktreeRoot()
+	if err != nil {
+		ui.PrintError(err, "Run this command from within a worktree-managed repository")
+		return
+	}
+
+	filter, _ := cmd.Flags().GetString("filter")
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	failFast, _ := cmd.Flags().GetBool("fail-fast")
+
+	// Listing is read-only, so it runs even in dry-run mode
+	paths, err := git.NewClient(root).WorktreeList()
+	if err != nil {
+		ui.PrintError(err, "Failed to list worktrees")
+		return
+	}
+	paths, err = filterWorktrees(root, paths, filter)
+	if err != nil {
+		ui.PrintError(err, "Use a glob such as 'feat-*' or 'feat/*'")
+		return
+	}
+	if len(paths) == 0 {
+		ui.PrintError(fmt.Errorf("no worktrees match %q", filter), "Use 'gwtm list' to see available worktrees")
+		return
+	}
+
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = worktreeName(root, path)
+	}
+	commandLine := strings.Join(args, " ")
+
+	if GetDryRun() {
+		ui.PrintDryRun(fmt.Sprintf("Would run '%s' in: %s", commandLine, strings.Join(names, ", ")))
+		return
+	}
+
+	parallel = min(max(parallel, 1), len(paths))
+	ui.PrintStatus("▶️", fmt.Sprintf("Running '%s' in %d worktrees (%d at a time)", commandLine, len(paths), parallel))
+
+	results := make([]execResult, len(paths))
+	indexes := make(chan int)
+	var failed atomic.Bool
+	var printMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for range parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = execResult{Name: names[i]}
+				if failFast && failed.Load() {
+					continue
+				}
+
+				if parallel == 1 {
+					// Run alone, so the command can use the terminal directly
+					ui.PrintStatus("📂", names[i])
+					results[i] = runInWorktree(paths[i], args, os.Stdin, os.Stdout, os.Stderr)
+				} else {
+					var output bytes.Buffer
+					results[i] = runInWorktree(paths[i], args, nil, &output, &output)
+					printMu.Lock()
+					ui.PrintStatus("📂", names[i])
+					os.Stdout.Write(output.Bytes())
+					printMu.Unlock()
+				}
+				results[i].Name = names[i]
+
+				if results[i].ExitCode != 0 {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	for i := range paths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	printExecSummary(results)
+}
+
+// filterWorktrees returns the paths whose directory name, relative to root,
+// matches glob. Slashes in glob are treated like slashes in branch names.
+func filterWorktrees(root string, paths []string, glob string) ([]string, error) {
+	if glob == "" {
+		return paths, nil
+	}
+
+	pattern := worktreeDirName(glob)
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid --filter %q: %w", glob, err)
+	}
+
+	var matched []string
+	for _, path := range paths {
+		if ok, _ := filepath.Match(pattern, worktreeName(root, path)); ok {
+			matched = append(matched, path)
+		}
+	}
+	return matched, nil
+}
+
+// worktreeName returns how a worktree is shown to the user: its path relative
+// to the project root, or the full path for worktrees outside it
+func worktreeName(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// runInWorktree runs args in dir and reports how it exited
+func runInWorktree(dir string, args []string, stdin io.Reader, stdout, stderr io.Writer) execResult {
+	command := exec.Command(args[0], args[1:]...)
+	command.Dir = dir
+	command.Stdin = stdin
+	command.Stdout = stdout
+	command.Stderr = stderr
+
+	start := time.Now()
+	err := command.Run()
+	result := execResult{Ran: true, Duration: time.Since(start)}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		result.ExitCode = -1
+		result.Err = err
+	}
+	return result
+}
+
+// printExecSummary reports each worktree's exit code and fails the command if
+// any of them failed
+func printExecSummary(results []execResult) {
+	width := 0
+	for _, result := range results {
+		width = max(width, len(result.Name))
+	}
+
+	var passed, failed, skipped int
+	lines := make([]string, len(results))
+	for i, result := range results {
+		var emoji, detail string
+		switch {
+		case !result.Ran:
+			skipped++
+			emoji, detail = "⏭️", "not run"
+		case result.Err != nil:
+			failed++
+			emoji, detail = "❌", "could not start: "+result.Err.Error()
+		case result.ExitCode != 0:
+			failed++
+			emoji, detail = "❌", fmt.Sprintf("exit %d (%s)", result.ExitCode, result.Duration.Round(time.Millisecond))
+		default:
+			passed++
+			emoji, detail = "✅", fmt.Sprintf("exit 0 (%s)", result.Duration.Round(time.Millisecond))
+		}
+		lines[i] = fmt.Sprintf("  %s %-*s  %s", emoji, width, result.Name, detail)
+	}
+
+	ui.PrintStatus("📊", fmt.Sprintf("Exec summary: %d passed, %d failed, %d not run", passed, failed, skipped))
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	if failed > 0 {
+		ui.PrintError(fmt.Errorf("command failed in %d worktree(s)", failed), "See the output above for each failing worktree")
+	}
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestFilterWorktrees(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "project")
+	paths := []string{
+		filepath.Join(root, "main"),
+		filepath.Join(root, "feat-login"),
+		filepath.Join(root, "feat-signup"),
+		filepath.Join(root, "fix-crash"),
+	}
+
+	tests := []struct {
+		glob    string
+		want    []string
+		wantErr bool
+	}{
+		{glob: "", want: []string{"main", "feat-login", "feat-signup", "fix-crash"}},
+		{glob: "feat-*", want: []string{"feat-login", "feat-signup"}},
+		{glob: "feat/*", want: []string{"feat-login", "feat-signup"}},
+		{glob: "f*-crash", want: []string{"fix-crash"}},
+		{glob: "release-*", want: nil},
+		{glob: "[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			got, err := filterWorktrees(root, paths, tt.glob)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("filterWorktrees(%q) error = %v, wantErr %v", tt.glob, err, tt.wantErr)
+			}
+			var names []string
+			for _, path := range got {
+				names = append(names, worktreeName(root, path))
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("filterWorktrees(%q) = %v, want %v", tt.glob, names, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunInWorktree(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands in this test are shell commands")
+	}
+	dir := t.TempDir()
+
+	var output bytes.Buffer
+	result := runInWorktree(dir, []string{"sh", "-c", "pwd; exit 3"}, nil, &output, &output)
+	if !result.Ran || result.ExitCode != 3 || result.Err != nil {
+		t.Errorf("runInWorktree() = %+v, want exit code 3", result)
+	}
+	if got, _ := filepath.EvalSymlinks(string(bytes.TrimSpace(output.Bytes()))); got != mustEvalSymlinks(t, dir) {
+		t.Errorf("command ran in %q, want %q", got, dir)
+	}
+
+	result = runInWorktree(dir, []string{"gwtm-no-such-command"}, nil, &output, &output)
+	if result.ExitCode != -1 || result.Err == nil {
+		t.Errorf("runInWorktree() with a missing command = %+v, want a start error", result)
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/hooks"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+)
+
+// hookDirs returns the directories searched for hooks: the repository's own
+// .gwtm/hooks in the worktree hooksFrom, then the user's hooks directory.
+// Returns nil when hooks are disabled by --no-hooks or gwtm.hooks=false.
+func hookDirs(root, hooksFrom string) []string {
+	if GetNoHooks() || !getBoolSetting(root, "hooks") {
+		return nil
+	}
+	return []string{filepath.Join(hooksFrom, hooks.RepoHooksDir), config.GetHooksDir()}
+}
+
+// hasHooks reports whether any hook called name would run for the worktree hooksFrom
+func hasHooks(name, root, hooksFrom string) bool {
+	return len(hooks.Find(name, hookDirs(root, hooksFrom)...)) > 0
+}
+
+// runHooks runs the hooks called name from workDir, taking repository hooks
+// from the worktree hooksFrom. Repository hooks only run once the project
+// trusts them.
+func runHooks(name, hooksFrom, workDir string, env hooks.Env, status func(emoji, message string)) error {
+	dirs := hookDirs(env.ProjectRoot, hooksFrom)
+	if len(dirs) > 0 && len(hooks.Find(name, dirs[0])) > 0 && !trustRepoHooks(env.ProjectRoot, dirs[0], status) {
+		dirs = dirs[1:]
+	}
+	if len(hooks.Find(name, dirs...)) == 0 {
+		return nil
+	}
+
+	status("🪝", "Running "+name+" hooks")
+	return hooks.Run(name, dirs, workDir, env)
+}
+
+// trustRepoHooks reports whether hooks from the repository's hook directory
+// dir may run in the project at root. They come with the code that was
+// cloned, so unless --trust-hooks or hooks.trustRepo says so the user is
+// asked, and a yes is remembered in the project's .gwtm.toml. --yes does not
+// answer this question.
+func trustRepoHooks(root, dir string, status func(emoji, message string)) bool {
+	if GetTrustHooks() || getBoolSetting(root, "hooks.trustRepo") {
+		return true
+	}
+
+	var names []string
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if len(hooks.Find(entry.Name(), dir)) > 0 {
+			names = append(names, entry.Name())
+		}
+	}
+	question := "Run the repository's hooks (" + strings.Join(names, ", ") + ") from " + dir + "? They run as you, with no sandbox"
+
+	trusted := false
+	if interactive() {
+		var err error
+		if trusted, err = ui.PromptYesNo("🔐 "+question, os.Stdin); err != nil {
+			trusted = false
+		}
+	}
+	if !trusted {
+		status("⚠️", "Skipped the repository's hooks in "+dir)
+		status("💡", "Review them, then pass --trust-hooks or run 'gwtm config set --project hooks.trustRepo true'")
+		return false
+	}
+
+	if root != "" {
+		path := filepath.Join(root, config.ProjectConfigFile)
+		if err := config.SetInFile(path, "hooks.trustRepo", []string{"true"}); err != nil {
+			status("⚠️", "Could not remember the answer in "+path+": "+err.Error())
+		}
+	}
+	return true
+}
+
+// mainWorktree returns the worktree of the project's default branch, falling
+// back to any other worktree except exclude. Returns "" when there is none.
+// Only local refs are read, so it never waits on an unreachable origin.
+func mainWorktree(root, exclude string) string {
+	if branch, err := git.NewClient(root).LocalDefaultBranch(); err == nil {
+		path := filepath.Join(root, worktreeDirName(branch))
+		if _, err := os.Stat(path); err == nil && !samePath(path, exclude) {
+			return path
+		}
+	}
+	return submoduleShareSource(root, exclude)
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/hooks"
+)
+
+func TestRunHooks_RepoTrust(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are shell scripts here")
+	}
+
+	root, branch := setupTestProject(t)
+	t.Setenv(config.EnvName("hooks.trustRepo"), "")
+	worktree := filepath.Join(root, worktreeDirName(branch))
+	hookDir := filepath.Join(worktree, hooks.RepoHooksDir)
+	os.MkdirAll(hookDir, 0755)
+	marker := filepath.Join(root, "ran")
+	os.WriteFile(filepath.Join(hookDir, hooks.PostCreate), []byte("#!/bin/sh\ntouch \"$GWTM_PROJECT_ROOT/ran\"\n"), 0755)
+
+	oldTrust, oldYes, oldNoInput := trustHooks, assumeYes, noInput
+	defer func() { trustHooks, assumeYes, noInput = oldTrust, oldYes, oldNoInput }()
+	noInput = true
+
+	env := hooks.Env{Branch: branch, WorktreePath: worktree, ProjectRoot: root}
+	run := func() bool {
+		t.Helper()
+		os.Remove(marker)
+		if err := runHooks(hooks.PostCreate, worktree, worktree, env, func(string, string) {}); err != nil {
+			t.Fatalf("runHooks() error = %v", err)
+		}
+		_, err := os.Stat(marker)
+		return err == nil
+	}
+
+	// Untrusted repository hooks are skipped when gwtm cannot ask, even with --yes
+	assumeYes = true
+	if run() {
+		t.Error("runHooks() ran untrusted repository hooks")
+	}
+
+	trustHooks = true
+	if !run() {
+		t.Error("runHooks() with --trust-hooks did not run the repository hooks")
+	}
+
+	trustHooks = false
+	if err := config.SetInFile(filepath.Join(root, config.ProjectConfigFile), "hooks.trustRepo", []string{"true"}); err != nil {
+		t.Fatal(err)
+	}
+	if !run() {
+		t.Error("runHooks() with hooks.trustRepo did not run the repository hooks")
+	}
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/include"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+)
+
+// includePatterns returns the patterns of untracked files to bring into new
+// worktrees: those in the source worktree's .worktreeinclude followed by any
+// gwtm.include settings.
+func includePatterns(root, source string) ([]string, error) {
+	patterns, err := include.ReadPatterns(filepath.Join(source, include.FileName))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(patterns, loadSettings(root).GetAll("include")...), nil
+}
+
+// includeFiles brings the files matching the include patterns from the
+// source worktree into worktreePath, reporting each one through status.
+// Files that already exist in the new worktree are left alone. Returns the
+// number of files that could not be brought in.
+func includeFiles(root, source, worktreePath string, mode include.Mode, status func(emoji, message string)) int {
+	patterns, err := includePatterns(root, source)
+	if err != nil {
+		ui.PrintError(err, "Check "+include.FileName+" and gwtm.include")
+		return 1
+	}
+	if len(patterns) == 0 {
+		return 0
+	}
+
+	// Only untracked files can be brought in, and git lists them without
+	// searching directories such as node_modules/ file by file
+	candidates, err := git.NewClient(source).UntrackedPaths()
+	if err != nil {
+		ui.PrintError(err, "Check that "+source+" is a worktree")
+		return 1
+	}
+	matches, err := include.Match(source, candidates, patterns)
+	if err != nil {
+		ui.PrintError(err, "Check that "+source+" is readable")
+		return 1
+	}
+	if len(matches) == 0 {
+		return 0
+	}
+
+	status("📋", fmt.Sprintf("Bringing %d local file(s) from %s (%s)", len(matches), source, mode))
+
+	failed := 0
+	for _, rel := range matches {
+		err := include.Bring(source, worktreePath, rel, mode)
+		switch {
+		case err == nil:
+			status("📄", rel)
+		case errors.Is(err, include.ErrExists):
+			status("⏭️", rel+" already present — skipped")
+		default:
+			failed++
+			ui.PrintError(err, "Copy "+rel+" into the worktree manually")
+		}
+	}
+	return failed
+}
@@ -34,14 +34,44 @@ func runList(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	worktrees, err := client.WorktreeList()
+	worktrees, err := client.WorktreeListPorcelain()
 	if err != nil {
 		ui.PrintError(err, "Failed to list worktrees")
 		return
 	}
 
+	width := 0
+	for _, wt := range worktrees {
+		width = max(width, len(wt.Path))
+	}
+
 	ui.PrintStatus("📋", "Active Git worktrees:")
 	for _, wt := range worktrees {
-		fmt.Println(wt)
+		fmt.Printf("%-*s  %s\n", width, wt.Path, describeWorktree(wt))
+	}
+}
+
+// describeWorktree summarises what a worktree has checked out, in the style
+// of 'git worktree list'
+func describeWorktree(wt git.Worktree) string {
+	if wt.Bare {
+		return "(bare)"
+	}
+
+	head := wt.Head
+	if len(head) > 7 {
+		head = head[:7]
+	}
+
+	desc := head + " [" + wt.Branch + "]"
+	if wt.Detached {
+		desc = head + " (detached HEAD)"
+	}
+	if wt.Locked {
+		desc += " locked"
+	}
+	if wt.Prunable {
+		desc += " prunable"
 	}
+	return desc
 }
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+)
+
+func TestDescribeWorktree(t *testing.T) {
+	tests := []struct {
+		name string
+		wt   git.Worktree
+		want string
+	}{
+		{
+			name: "bare repository",
+			wt:   git.Worktree{Path: "/p/.bare", Bare: true},
+			want: "(bare)",
+		},
+		{
+			name: "branch",
+			wt:   git.Worktree{Path: "/p/main", Head: "0123456789abcdef", Branch: "main"},
+			want: "0123456 [main]",
+		},
+		{
+			name: "detached",
+			wt:   git.Worktree{Path: "/p/v1.0.0", Head: "fedcba9876543210", Detached: true},
+			want: "fedcba9 (detached HEAD)",
+		},
+		{
+			name: "locked and prunable",
+			wt:   git.Worktree{Path: "/p/gone", Head: "0123456789abcdef", Branch: "gone", Locked: true, Prunable: true},
+			want: "0123456 [gone] locked prunable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeWorktree(tt.wt); got != tt.want {
+				t.Errorf("describeWorktree() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var maintainCmd = &cobra.Command{
+	Use:   "maintain",
+	Short: "Garbage-collect and optimise the shared repository",
+	Long: `Run git's maintenance tasks — garbage collection and a commit-graph rewrite —
+on the project's .bare repository and report the space reclaimed.
+
+--schedule registers the repository with 'git maintenance start', so git keeps
+it maintained in the background (hourly prefetch and commit-graph, daily loose
+object cleanup, weekly repacking). --unschedule removes it again.`,
+	Args: cobra.NoArgs,
+	Run:  runMaintain,
+}
+
+func init() {
+	maintainCmd.Flags().Bool("schedule", false, "Register the repository for scheduled background maintenance")
+	maintainCmd.Flags().Bool("unschedule", false, "Remove the repository from scheduled background maintenance")
+	maintainCmd.MarkFlagsMutuallyExclusive("schedule", "unschedule")
+	rootCmd.AddCommand(maintainCmd)
+}
+
+func runMaintain(cmd *cobra.Command, args []string) {
+	schedule, _ := cmd.Flags().GetBool("schedule")
+	unschedule, _ := cmd.Flags().GetBool("unschedule")
+
+	root, err := findWorktreeRoot()
+	if err != nil {
+		ui.PrintError(err, "Run this command from within a worktree-managed repository")
+		return
+	}
+
+	bareDir := filepath.Join(root, ".bare")
+	client := git.NewClient(bareDir)
+	client.DryRun = GetDryRun()
+
+	switch {
+	case schedule:
+		if err := scheduleMaintenance(client, ui.PrintStatus); err != nil {
+			printGuidedError(err, "Failed to schedule maintenance")
+			return
+		}
+		if !client.DryRun {
+			ui.PrintStatus("✅", "Background maintenance scheduled — 'gwtm maintain --unschedule' stops it")
+		}
+		return
+	case unschedule:
+		if client.DryRun {
+			ui.PrintDryRun("Would remove the repository from scheduled maintenance")
+			return
+		}
+		if err := client.MaintenanceUnregister(); err != nil {
+			ui.PrintError(err, "Check 'git config --global --get-all maintenance.repo'")
+			return
+		}
+		ui.PrintStatus("✅", "Background maintenance unscheduled")
+		return
+	}
+
+	if client.DryRun {
+		ui.PrintDryRun("Would garbage-collect and write the commit-graph for " + bareDir)
+		return
+	}
+
+	before, err := dirSize(bareDir)
+	if err != nil {
+		ui.PrintError(err, "Failed to measure the repository")
+		return
+	}
+
+	ui.PrintStatus("🧹", "Garbage-collecting and writing the commit-graph")
+	if err := client.Maintain(); err != nil {
+		ui.PrintError(err, "Run 'gwtm doctor' to check the repository")
+		return
+	}
+
+	after, err := dirSize(bareDir)
+	if err != nil {
+		ui.PrintError(err, "Failed to measure the repository")
+		return
+	}
+
+	reclaimed := "no space reclaimed"
+	if before > after {
+		reclaimed = formatBytes(before-after) + " reclaimed"
+	}
+	ui.PrintStatus("✅", fmt.Sprintf("Maintenance complete: .bare is %s (%s)", formatBytes(after), reclaimed))
+}
+
+// scheduleMaintenance registers the bare repository for git's background
+// maintenance, which needs git 2.31 or later, reporting progress through status
+func scheduleMaintenance(client *git.Client, status func(emoji, message string)) error {
+	if !client.SupportsMaintenance() {
+		return withGuidance(fmt.Errorf("scheduled maintenance needs git 2.31 or later"), "Upgrade git, or run 'gwtm maintain' from time to time instead")
+	}
+	if client.DryRun {
+		ui.PrintDryRun("Would register the repository for scheduled background maintenance")
+		return nil
+	}
+
+	status("🗓️", "Registering for scheduled background maintenance")
+	if err := client.MaintenanceStart(); err != nil {
+		return withGuidance(err, "git could not set up its scheduler (cron, launchd, systemd or Task Scheduler) — see 'git help maintenance'")
+	}
+	return nil
+}
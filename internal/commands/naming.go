@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// namingPolicy is a project's rules for branch names, read from the branch.* settings
+type namingPolicy struct {
+	Pattern   *regexp.Regexp // Whole name must match; nil when unset
+	Prefixes  []string       // Name must start with one of these; empty when unset
+	MaxLength int            // 0 when unset
+	Template  string         // Used to build names from --type/--ticket
+}
+
+// loadNamingPolicy reads the naming policy that applies in root
+func loadNamingPolicy(root string) (namingPolicy, error) {
+	settings := loadSettings(root)
+	policy := namingPolicy{
+		Template: settings.Get("branch.template"),
+		Prefixes: settings.GetAll("branch.prefix"),
+	}
+
+	if pattern := settings.Get("branch.pattern"); pattern != "" {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return policy, fmt.Errorf("invalid gwtm.branch.pattern %q: %w", pattern, err)
+		}
+		policy.Pattern = re
+	}
+
+	if maxLength := settings.Get("branch.maxLength"); maxLength != "" {
+		n, err := strconv.Atoi(maxLength)
+		if err != nil || n < 0 {
+			return policy, fmt.Errorf("invalid gwtm.branch.maxLength %q: must be a non-negative number (0 for no limit)", maxLength)
+		}
+		policy.MaxLength = n
+	}
+
+	return policy, nil
+}
+
+// validate reports the first rule that name breaks
+func (p namingPolicy) validate(name string) error {
+	if p.MaxLength > 0 && len(name) > p.MaxLength {
+		return fmt.Errorf("branch name %q is %d characters long; the limit is %d", name, len(name), p.MaxLength)
+	}
+
+	if len(p.Prefixes) > 0 {
+		allowed := false
+		for _, prefix := range p.Prefixes {
+			if strings.HasPrefix(name, prefix) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("branch name %q must start with one of: %s", name, strings.Join(p.Prefixes, ", "))
+		}
+	}
+
+	if p.Pattern != nil && !p.Pattern.MatchString(name) {
+		return fmt.Errorf("branch name %q does not match the pattern %s", name, p.Pattern)
+	}
+
+	return nil
+}
+
+// describe summarises the policy for error guidance
+func (p namingPolicy) describe() string {
+	var rules []string
+	if len(p.Prefixes) > 0 {
+		rules = append(rules, "prefixes "+strings.Join(p.Prefixes, ", "))
+	}
+	if p.Pattern != nil {
+		rules = append(rules, "pattern "+p.Pattern.String())
+	}
+	if p.MaxLength > 0 {
+		rules = append(rules, fmt.Sprintf("at most %d characters", p.MaxLength))
+	}
+	if len(rules) == 0 {
+		return "Choose a valid git branch name"
+	}
+	return "This project requires " + strings.Join(rules, "; ") + " — or use --type/--ticket to build the name"
+}
+
+var (
+	slugInvalidRegex     = regexp.MustCompile(`[^a-z0-9]+`)
+	emptyPlaceholderJoin = regexp.MustCompile(`([/_-])[-_]+|[-_]+([/])`)
+)
+
+// slugify turns a free-text description into a lowercase, hyphenated name part
+func slugify(s string) string {
+	return strings.Trim(slugInvalidRegex.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// renderBranchName fills template's {type}, {ticket} and {slug} placeholders.
+// Separators left dangling by empty placeholders are removed, so the default
+// template without a ticket gives "feat/add-login". When maxLength is set, the
+// slug is shortened at a word boundary so the name fits.
+func renderBranchName(template, branchType, ticket, description string, maxLength int) string {
+	slug := slugify(description)
+	render := func(slug string) string {
+		name := strings.NewReplacer("{type}", branchType, "{ticket}", ticket, "{slug}", slug).Replace(template)
+		name = emptyPlaceholderJoin.ReplaceAllString(name, "$1$2")
+		return strings.Trim(name, "/-_")
+	}
+
+	name := render(slug)
+	for maxLength > 0 && len(name) > maxLength && slug != "" {
+		if i := strings.LastIndex(slug, "-"); i > 0 {
+			slug = slug[:i]
+		} else {
+			slug = ""
+		}
+		name = render(slug)
+	}
+	return name
+}
+
+// worktreeDirName is the directory a branch's worktree lives in. Slashes
+// become hyphens so that feat/login gets a single directory, feat-login.
+func worktreeDirName(branch string) string {
+	return strings.NewReplacer("/", "-", `\`, "-").Replace(branch)
+}
+
+// checkWorktreeDir returns an error when path, the directory branch's
+// worktree would get, already exists. Branches whose names differ only in
+// slashes and hyphens, such as feat/login and feat-login, share a directory.
+func checkWorktreeDir(root, path, branch string) error {
+	if _, err := os.Lstat(path); err != nil {
+		return nil
+	}
+	if wt, ok := findWorktree(root, path); ok && wt.Branch != "" {
+		if wt.Branch == branch {
+			return fmt.Errorf("branch '%s' already has a worktree at %s", branch, path)
+		}
+		return fmt.Errorf("'%s' would use the directory %s, which is already the worktree of '%s'", branch, path, wt.Branch)
+	}
+	return fmt.Errorf("'%s' would use the directory %s, which already exists", branch, path)
+}
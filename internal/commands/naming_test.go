@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+)
+
+// defaultBranchTemplate is the default of the branch.template setting
+var defaultBranchTemplate = func() string {
+	k, _ := config.LookupKey("branch.template")
+	return k.Default
+}()
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "add login", want: "add-login"},
+		{input: "  Fix: crash on   startup!! ", want: "fix-crash-on-startup"},
+		{input: "Ünïcode & symbols", want: "n-code-symbols"},
+		{input: "already-slugged", want: "already-slugged"},
+		{input: "!!!", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := slugify(tt.input); got != tt.want {
+				t.Errorf("slugify(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderBranchName(t *testing.T) {
+	tests := []struct {
+		name        string
+		template    string
+		branchType  string
+		ticket      string
+		description string
+		maxLength   int
+		want        string
+	}{
+		{
+			name:     "all parts",
+			template: defaultBranchTemplate, branchType: "feat", ticket: "ABC-12", description: "add login",
+			want: "feat/ABC-12-add-login",
+		},
+		{
+			name:     "no ticket",
+			template: defaultBranchTemplate, branchType: "fix", description: "Crash on startup",
+			want: "fix/crash-on-startup",
+		},
+		{
+			name:     "no type",
+			template: defaultBranchTemplate, ticket: "ABC-12", description: "add login",
+			want: "ABC-12-add-login",
+		},
+		{
+			name:     "no description",
+			template: defaultBranchTemplate, branchType: "feat", ticket: "ABC-12",
+			want: "feat/ABC-12",
+		},
+		{
+			name:     "custom template",
+			template: "{ticket}_{slug}", ticket: "OPS-7", description: "rotate keys",
+			want: "OPS-7_rotate-keys",
+		},
+		{
+			name:     "shortened to fit at a word boundary",
+			template: defaultBranchTemplate, branchType: "feat", ticket: "ABC-12", description: "add login with single sign on",
+			maxLength: 30,
+			want:      "feat/ABC-12-add-login-with",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderBranchName(tt.template, tt.branchType, tt.ticket, tt.description, tt.maxLength)
+			if got != tt.want {
+				t.Errorf("renderBranchName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamingPolicyValidate(t *testing.T) {
+	policy := namingPolicy{
+		Pattern:   regexp.MustCompile(`^(?:(feat|fix)/[A-Z]+-[0-9]+-[a-z0-9-]+)$`),
+		Prefixes:  []string{"feat/", "fix/"},
+		MaxLength: 40,
+	}
+
+	tests := []struct {
+		name    string
+		branch  string
+		wantErr bool
+	}{
+		{name: "valid", branch: "feat/ABC-12-add-login", wantErr: false},
+		{name: "wrong prefix", branch: "chore/ABC-12-tidy", wantErr: true},
+		{name: "no ticket", branch: "feat/add-login", wantErr: true},
+		{name: "too long", branch: "feat/ABC-12-a-very-long-description-of-the-change", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.validate(tt.branch)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate(%q) error = %v, wantErr %v", tt.branch, err, tt.wantErr)
+			}
+		})
+	}
+
+	// An empty policy allows anything
+	if err := (namingPolicy{}).validate("anything-goes"); err != nil {
+		t.Errorf("empty policy validate() error = %v", err)
+	}
+}
+
+func TestLoadNamingPolicy(t *testing.T) {
+	root, _ := setupTestProject(t)
+	client := git.NewClient(root)
+	client.SetConfig("gwtm.branch.pattern", `(feat|fix)/.+`)
+	client.ExecGit("config", "--add", "gwtm.branch.prefix", "feat/")
+	client.ExecGit("config", "--add", "gwtm.branch.prefix", "fix/")
+	client.SetConfig("gwtm.branch.maxLength", "50")
+
+	policy, err := loadNamingPolicy(root)
+	if err != nil {
+		t.Fatalf("loadNamingPolicy() error = %v", err)
+	}
+	if len(policy.Prefixes) != 2 || policy.MaxLength != 50 || policy.Template != defaultBranchTemplate {
+		t.Errorf("loadNamingPolicy() = %+v", policy)
+	}
+	// The pattern is anchored to the whole name
+	if policy.Pattern.MatchString("x-feat/login") {
+		t.Error("loadNamingPolicy() pattern is not anchored")
+	}
+
+	client.SetConfig("gwtm.branch.pattern", `(unclosed`)
+	if _, err := loadNamingPolicy(root); err == nil {
+		t.Error("loadNamingPolicy() with invalid pattern error = nil, want error")
+	}
+}
+
+func TestWorktreeDirName(t *testing.T) {
+	if got := worktreeDirName("feat/ABC-12-login"); got != "feat-ABC-12-login" {
+		t.Errorf("worktreeDirName() = %q, want feat-ABC-12-login", got)
+	}
+	if got := worktreeDirName("main"); got != "main" {
+		t.Errorf("worktreeDirName() = %q, want main", got)
+	}
+}
+
+func TestCheckWorktreeDir(t *testing.T) {
+	root, branch := setupTestProject(t)
+	client := git.NewClient(root)
+	client.ExecGit("branch", "feat-login", branch)
+	if err := client.WorktreeAdd(filepath.Join(root, "feat-login"), "feat-login", false); err != nil {
+		t.Fatalf("WorktreeAdd() error = %v", err)
+	}
+	os.MkdirAll(filepath.Join(root, "scratch"), 0755)
+
+	tests := []struct {
+		branch  string
+		wantErr string
+	}{
+		{branch: "feat/signup"},
+		{branch: "feat/login", wantErr: "already the worktree of 'feat-login'"},
+		{branch: "feat-login", wantErr: "already has a worktree"},
+		{branch: "scratch", wantErr: "already exists"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			err := checkWorktreeDir(root, filepath.Join(root, worktreeDirName(tt.branch)), tt.branch)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkWorktreeDir() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkWorktreeDir() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/steps"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// pullRequestRef returns the ref under which forge publishes the head of
+// pull request number
+func pullRequestRef(forge string, number int) (string, error) {
+	switch forge {
+	case "github":
+		return fmt.Sprintf("refs/pull/%d/head", number), nil
+	case "gitlab":
+		return fmt.Sprintf("refs/merge-requests/%d/head", number), nil
+	default:
+		return "", fmt.Errorf("unknown forge %q: must be github or gitlab", forge)
+	}
+}
+
+// detectForge returns the forge hosting origin: gwtm.forge when set,
+// otherwise gitlab for origin URLs mentioning GitLab, otherwise github
+func detectForge(root string) string {
+	if forge := getSetting(root, "forge"); forge != "" {
+		return strings.ToLower(forge)
+	}
+	url, _ := git.NewClient(root).GetConfig("remote.origin.url")
+	if strings.Contains(strings.ToLower(url), "gitlab") {
+		return "gitlab"
+	}
+	return "github"
+}
+
+// pullRequestLocalRef is where a pull request's head is fetched to. It lives
+// outside refs/remotes/origin so 'git fetch --prune' leaves it alone.
+func pullRequestLocalRef(number int) string {
+	return fmt.Sprintf("refs/gwtm/pr/%d", number)
+}
+
+// runPullRequest creates, or with update refreshes, the worktree for a pull request
+func runPullRequest(cmd *cobra.Command, root string, number int, update bool) {
+	if number <= 0 {
+		ui.PrintError(fmt.Errorf("invalid pull request number %d", number), "Pass the number shown on the pull request, e.g. --pr 1234")
+		return
+	}
+
+	branchName := fmt.Sprintf("pr-%d", number)
+	worktreePath := filepath.Join(root, branchName)
+	localRef := pullRequestLocalRef(number)
+
+	forge := detectForge(root)
+	ref, err := pullRequestRef(forge, number)
+	if err != nil {
+		ui.PrintError(err, "Set gwtm.forge to github or gitlab")
+		return
+	}
+
+	_, statErr := os.Stat(worktreePath)
+	exists := statErr == nil
+	if exists {
+		// The directory may belong to another branch, such as pr/1234
+		if wt, ok := findWorktree(root, worktreePath); !ok || wt.Branch != branchName {
+			ui.PrintError(checkWorktreeDir(root, worktreePath, branchName), "Remove or rename what is at "+worktreePath+" first")
+			return
+		}
+	}
+	if exists && !update {
+		ui.PrintError(
+			fmt.Errorf("worktree for pull request #%d already exists at %s", number, worktreePath),
+			"Use --update to fetch the latest changes into it",
+		)
+		return
+	}
+
+	extras, err := loadWorktreeExtras(cmd, root, worktreePath)
+	if err != nil {
+		printGuidedError(err, "Check the new-branch flags")
+		return
+	}
+
+	client := git.NewClient(root)
+	client.DryRun = GetDryRun()
+
+	if client.DryRun {
+		ui.PrintDryRun(fmt.Sprintf("Would fetch %s from origin into %s", ref, localRef))
+		if exists {
+			ui.PrintDryRun("Would fast-forward '" + branchName + "' to the latest pull request head")
+			return
+		}
+		ui.PrintDryRun("Would create branch '" + branchName + "' and a worktree for it")
+		extras.printDryRun(root, worktreePath)
+		return
+	}
+
+	ui.PrintStatus("📡", fmt.Sprintf("Fetching pull request #%d (%s)", number, ref))
+	if err := client.FetchRefspecs("origin", "+"+ref+":"+localRef); err != nil {
+		ui.PrintError(err, fmt.Sprintf("Check that pull request #%d exists — origin is treated as %s; set gwtm.forge if that is wrong", number, forge))
+		return
+	}
+
+	if exists {
+		if err := fastForwardPullRequest(worktreePath, localRef); err != nil {
+			printGuidedError(err, "Update the worktree manually")
+			return
+		}
+		ui.PrintStatus("✅", fmt.Sprintf("Worktree for pull request #%d is up to date at: %s", number, worktreePath))
+		return
+	}
+
+	branchExisted := client.BranchExists(branchName, false)
+	runner := steps.NewRunner(ui.PrintStatus)
+	if !branchExisted {
+		err := runner.Run(steps.Step{
+			Emoji: "🌱",
+			Name:  "Creating branch '" + branchName + "'",
+			Do:    func() error { return client.CreateBranch(branchName, localRef) },
+			Undo:  func() error { return client.DeleteBranch(branchName, true) },
+		})
+		if err != nil {
+			ui.PrintError(err, "Failed to create branch")
+			return
+		}
+	}
+
+	err = runner.Run(steps.Step{
+		Name: "Creating worktree at " + worktreePath,
+		Do:   func() error { return client.WorktreeAdd(worktreePath, branchName, false) },
+	})
+	if err != nil {
+		runner.PrintSummary()
+		ui.PrintError(err, "Failed to create worktree")
+		return
+	}
+
+	// A branch left behind by an earlier review may be behind the pull request
+	if branchExisted {
+		wt := git.NewClient(worktreePath)
+		branchHead, _ := wt.ResolveCommit("HEAD")
+		pullRequestHead, _ := wt.ResolveCommit(localRef)
+		if branchHead != pullRequestHead && fastForwardPullRequest(worktreePath, localRef) != nil {
+			ui.PrintStatus("⚠️", fmt.Sprintf("'%s' is left over from an earlier review and has diverged from %s, so it stays at its old commit", branchName, localRef))
+			ui.PrintStatus("💡", "To take the pull request's version, run 'git reset --hard "+localRef+"' in "+worktreePath+"; --update keeps it current afterwards")
+		}
+	}
+
+	finishWorktree(root, worktreePath, branchName, "", extras)
+
+	ui.PrintStatus("✅", fmt.Sprintf("Worktree for pull request #%d is ready at: %s", number, worktreePath))
+}
+
+// fastForwardPullRequest brings the pull request worktree at worktreePath up
+// to date with the fetched head at localRef
+func fastForwardPullRequest(worktreePath, localRef string) error {
+	wt := git.NewClient(worktreePath)
+
+	clean, err := wt.IsClean()
+	if err != nil {
+		return err
+	}
+	if !clean {
+		return withGuidance(
+			fmt.Errorf("%s has uncommitted changes", worktreePath),
+			"Commit or stash them, then run the update again",
+		)
+	}
+
+	ui.PrintStatus("⏩", "Fast-forwarding to the latest pull request head")
+	if err := wt.MergeFastForward(localRef); err != nil {
+		return withGuidance(err,
+			"The pull request history was rewritten (e.g. force-pushed). To discard your local state, run 'git reset --hard "+localRef+"' in "+worktreePath)
+	}
+	return nil
+}
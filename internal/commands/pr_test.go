@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/spf13/cobra"
+)
+
+func TestPullRequestRef(t *testing.T) {
+	tests := []struct {
+		forge   string
+		want    string
+		wantErr bool
+	}{
+		{forge: "github", want: "refs/pull/42/head"},
+		{forge: "gitlab", want: "refs/merge-requests/42/head"},
+		{forge: "bitbucket", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.forge, func(t *testing.T) {
+			got, err := pullRequestRef(tt.forge, 42)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pullRequestRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("pullRequestRef() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectForge(t *testing.T) {
+	root, _ := setupTestProject(t)
+	client := git.NewClient(root)
+
+	if got := detectForge(root); got != "github" {
+		t.Errorf("detectForge() for a plain URL = %q, want github", got)
+	}
+
+	client.SetConfig("remote.origin.url", "git@gitlab.example.com:group/repo.git")
+	if got := detectForge(root); got != "gitlab" {
+		t.Errorf("detectForge() for a GitLab URL = %q, want gitlab", got)
+	}
+
+	client.SetConfig("gwtm.forge", "GitHub")
+	if got := detectForge(root); got != "github" {
+		t.Errorf("detectForge() with gwtm.forge = %q, want github", got)
+	}
+}
+
+func TestRunPullRequest(t *testing.T) {
+	root, _ := setupTestProject(t)
+	srcDir := filepath.Join(filepath.Dir(root), "src")
+	upstreamDir := filepath.Join(filepath.Dir(root), "upstream.git")
+	src := git.NewClient(srcDir)
+
+	// publish adds a commit touching file and publishes it as pull request #5
+	publish := func(file string) {
+		t.Helper()
+		os.WriteFile(filepath.Join(srcDir, file), []byte(file+"\n"), 0644)
+		src.ExecGit("add", file)
+		src.ExecGit("commit", "-m", "Add "+file)
+		if _, _, err := src.ExecGit("push", "--force", upstreamDir, "HEAD:refs/pull/5/head"); err != nil {
+			t.Fatalf("failed to publish pull request: %v", err)
+		}
+	}
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		addWorktreeExtrasFlags(cmd)
+		return cmd
+	}
+
+	src.ExecGit("checkout", "-b", "contribution")
+	publish("first.txt")
+
+	runPullRequest(newCmd(), root, 5, false)
+
+	worktreePath := filepath.Join(root, "pr-5")
+	if _, err := os.Stat(filepath.Join(worktreePath, "first.txt")); err != nil {
+		t.Fatalf("runPullRequest() did not check out the pull request: %v", err)
+	}
+
+	// The author pushes again; --update fast-forwards the worktree
+	publish("second.txt")
+	runPullRequest(newCmd(), root, 5, true)
+
+	if _, err := os.Stat(filepath.Join(worktreePath, "second.txt")); err != nil {
+		t.Errorf("runPullRequest() with update did not bring in the new commit: %v", err)
+	}
+
+	// A pr-5 branch left behind by an earlier review is brought up to date
+	if err := git.NewClient(root).WorktreeRemove(worktreePath); err != nil {
+		t.Fatalf("WorktreeRemove() error = %v", err)
+	}
+	publish("third.txt")
+	runPullRequest(newCmd(), root, 5, false)
+
+	if _, err := os.Stat(filepath.Join(worktreePath, "third.txt")); err != nil {
+		t.Errorf("runPullRequest() reused the old pr-5 branch without fast-forwarding it: %v", err)
+	}
+}
@@ -0,0 +1,383 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/registry"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects",
+	Short: "List every project gwtm manages",
+	Long: `List the projects recorded in the registry, with how many worktrees each has
+and how many of those have local changes.
+
+'gwtm setup' records every project it creates. Record projects set up before
+the registry existed with 'gwtm projects add', and forget moved or deleted
+ones with 'gwtm doctor --fix'.`,
+	Args: cobra.NoArgs,
+	Run:  runProjects,
+}
+
+var projectsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the worktrees that need attention across all projects",
+	Long: `Show every worktree, across all projects, that has local changes, commits
+that are not pushed, commits to pull, or an upstream branch that is gone.
+Use --all to show clean worktrees too.`,
+	Args: cobra.NoArgs,
+	Run:  runProjectsStatus,
+}
+
+var projectsPathCmd = &cobra.Command{
+	Use:   "path <project>[/<worktree>]",
+	Short: "Print the path of a project or one of its worktrees",
+	Long: `Print the path of a project, or of one of its worktrees given by branch or
+directory name, for use in scripts and shell functions:
+
+  cd "$(gwtm projects path webapp/feat/login)"`,
+	Args: cobra.ExactArgs(1),
+	Run:  runProjectsPath,
+}
+
+var projectsAddCmd = &cobra.Command{
+	Use:   "add [dir]",
+	Short: "Record an existing project in the registry",
+	Long:  "Record the project at dir, or the current project, in the registry.",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runProjectsAdd,
+}
+
+var projectsRemoveCmd = &cobra.Command{
+	Use:   "remove <project>",
+	Short: "Forget a project without deleting it",
+	Long:  "Remove a project, given by name or path, from the registry. Nothing on disk is deleted.",
+	Args:  cobra.ExactArgs(1),
+	Run:   runProjectsRemove,
+}
+
+func init() {
+	projectsStatusCmd.Flags().Bool("all", false, "Also show clean, fully pushed worktrees")
+	projectsCmd.AddCommand(projectsStatusCmd, projectsPathCmd, projectsAddCmd, projectsRemoveCmd)
+	rootCmd.AddCommand(projectsCmd)
+}
+
+// projectSummary is the state of one registered project
+type projectSummary struct {
+	Project   registry.Project
+	Missing   bool // The project directory no longer holds a .bare repository
+	Worktrees []worktreeState
+	Err       error
+}
+
+// Dirty returns the number of worktrees with local changes
+func (s projectSummary) Dirty() int {
+	dirty := 0
+	for _, wt := range s.Worktrees {
+		if wt.Changes > 0 {
+			dirty++
+		}
+	}
+	return dirty
+}
+
+// worktreeState is what needs doing in one worktree
+type worktreeState struct {
+	Name     string // Path relative to the project root
+	Worktree git.Worktree
+	Changes  int // Files with staged, unstaged or untracked changes
+	Upstream git.Upstream
+}
+
+// NeedsAttention reports whether the worktree has anything to commit, push
+// or pull, or tracks a branch that is gone
+func (s worktreeState) NeedsAttention() bool {
+	return s.Changes > 0 || s.Upstream.Ahead > 0 || s.Upstream.Behind > 0 || s.Upstream.Gone
+}
+
+func runProjects(cmd *cobra.Command, args []string) {
+	projects, ok := loadProjects()
+	if !ok {
+		return
+	}
+
+	summaries := summarizeProjects(projects)
+
+	nameWidth, pathWidth := 0, 0
+	for _, s := range summaries {
+		nameWidth = max(nameWidth, len(s.Project.Name))
+		pathWidth = max(pathWidth, len(s.Project.Path))
+	}
+
+	ui.PrintStatus("📚", "Managed projects:")
+	missing := 0
+	for _, s := range summaries {
+		var state string
+		switch {
+		case s.Missing:
+			state = "missing"
+			missing++
+		case s.Err != nil:
+			state = "error: " + strings.SplitN(s.Err.Error(), "\n", 2)[0]
+		default:
+			state = fmt.Sprintf("%d worktree(s), %d with changes", len(s.Worktrees), s.Dirty())
+		}
+		fmt.Printf("  %-*s  %-*s  %s\n", nameWidth, s.Project.Name, pathWidth, s.Project.Path, state)
+	}
+
+	if missing > 0 {
+		ui.PrintStatus("💡", "Run 'gwtm doctor --fix' in any project to forget missing ones, or 'gwtm projects remove <name>'")
+	}
+}
+
+func runProjectsStatus(cmd *cobra.Command, args []string) {
+	all, _ := cmd.Flags().GetBool("all")
+
+	projects, ok := loadProjects()
+	if !ok {
+		return
+	}
+
+	shown := 0
+	for _, s := range summarizeProjects(projects) {
+		switch {
+		case s.Missing:
+			ui.PrintStatus("❓", fmt.Sprintf("%s: %s no longer exists", s.Project.Name, s.Project.Path))
+			continue
+		case s.Err != nil:
+			ui.PrintError(s.Err, "Run 'gwtm doctor' in "+s.Project.Path)
+			continue
+		}
+
+		var rows []worktreeState
+		for _, wt := range s.Worktrees {
+			if all || wt.NeedsAttention() {
+				rows = append(rows, wt)
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		width := 0
+		for _, wt := range rows {
+			width = max(width, len(wt.Name))
+		}
+		ui.PrintStatus("📂", s.Project.Name+" ("+s.Project.Path+")")
+		for _, wt := range rows {
+			fmt.Printf("  %-*s  %s\n", width, wt.Name, describeWorktreeState(wt))
+		}
+		shown += len(rows)
+	}
+
+	if shown == 0 && !all {
+		ui.PrintStatus("✅", fmt.Sprintf("All worktrees in %d project(s) are clean and in sync with their upstreams", len(projects)))
+	}
+}
+
+// describeWorktreeState summarises the local changes and upstream of a worktree
+func describeWorktreeState(wt worktreeState) string {
+	var parts []string
+	if wt.Changes > 0 {
+		parts = append(parts, fmt.Sprintf("%d changed", wt.Changes))
+	} else {
+		parts = append(parts, "clean")
+	}
+
+	switch {
+	case wt.Worktree.Detached:
+		parts = append(parts, "detached HEAD")
+	case wt.Upstream.Gone:
+		parts = append(parts, "upstream "+wt.Upstream.Name+" gone")
+	case wt.Upstream.Name == "":
+		parts = append(parts, "no upstream")
+	default:
+		if wt.Upstream.Ahead > 0 {
+			parts = append(parts, fmt.Sprintf("%d to push", wt.Upstream.Ahead))
+		}
+		if wt.Upstream.Behind > 0 {
+			parts = append(parts, fmt.Sprintf("%d to pull", wt.Upstream.Behind))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func runProjectsPath(cmd *cobra.Command, args []string) {
+	path, err := resolveProjectPath(config.GetRegistryPath(), args[0])
+	if err != nil {
+		ui.PrintError(err, "Use 'gwtm projects' to see managed projects")
+		return
+	}
+	fmt.Println(path)
+}
+
+// resolveProjectPath turns project or project/worktree into a path, using the
+// registry at registryPath. The worktree may be given by branch or directory name.
+func resolveProjectPath(registryPath, spec string) (string, error) {
+	name, worktree, _ := strings.Cut(spec, "/")
+
+	reg, err := registry.Load(registryPath)
+	if err != nil {
+		return "", err
+	}
+	project, err := reg.Find(name)
+	if err != nil {
+		return "", err
+	}
+
+	path := project.Path
+	if worktree != "" {
+		path = filepath.Join(path, worktreeDirName(worktree))
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("%s does not exist", path)
+	}
+	return path, nil
+}
+
+func runProjectsAdd(cmd *cobra.Command, args []string) {
+	var root string
+	var err error
+	if len(args) == 1 {
+		root, err = filepath.Abs(args[0])
+	} else {
+		root, err = findProjectRoot()
+	}
+	if err != nil {
+		ui.PrintError(err, "Run this command from within a worktree-managed repository, or pass its directory")
+		return
+	}
+	if info, err := os.Stat(filepath.Join(root, ".bare")); err != nil || !info.IsDir() {
+		ui.PrintError(fmt.Errorf("%s is not a worktree-managed project", root), "Pass the directory containing .bare")
+		return
+	}
+
+	if GetDryRun() {
+		ui.PrintDryRun("Would record " + root + " in the project registry")
+		return
+	}
+
+	if err := registerProject(root); err != nil {
+		ui.PrintError(err, "Check that "+config.GetStateDir()+" is writable")
+		return
+	}
+	ui.PrintStatus("✅", "Recorded "+filepath.Base(root)+" ("+root+")")
+}
+
+func runProjectsRemove(cmd *cobra.Command, args []string) {
+	registryPath := config.GetRegistryPath()
+	reg, err := registry.Load(registryPath)
+	if err != nil {
+		ui.PrintError(err, "Check "+registryPath)
+		return
+	}
+
+	// A path picks one of several projects that share a name
+	project, err := reg.Find(args[0])
+	if err != nil {
+		abs, _ := filepath.Abs(args[0])
+		i := slices.IndexFunc(reg.Projects, func(p registry.Project) bool { return p.Path == abs })
+		if i < 0 {
+			ui.PrintError(err, "Pass the project's name or its full path")
+			return
+		}
+		project = reg.Projects[i]
+	}
+
+	if GetDryRun() {
+		ui.PrintDryRun("Would forget " + project.Name + " (" + project.Path + ")")
+		return
+	}
+
+	err = registry.Update(registryPath, func(r *registry.Registry) {
+		r.Remove(project.Path)
+	})
+	if err != nil {
+		ui.PrintError(err, "Check that "+config.GetStateDir()+" is writable")
+		return
+	}
+	ui.PrintStatus("✅", "Forgot "+project.Name+" — its files were left in "+project.Path)
+}
+
+// loadProjects reads the registry, reporting problems and an empty registry
+func loadProjects() ([]registry.Project, bool) {
+	reg, err := registry.Load(config.GetRegistryPath())
+	if err != nil {
+		ui.PrintError(err, "Fix or delete "+config.GetRegistryPath())
+		return nil, false
+	}
+	if len(reg.Projects) == 0 {
+		ui.PrintStatus("📭", "No projects recorded yet — 'gwtm setup' records new ones, 'gwtm projects add' existing ones")
+		return nil, false
+	}
+	return reg.Projects, true
+}
+
+// registerProject records the project at root in the registry
+func registerProject(root string) error {
+	url, _ := git.NewClient(filepath.Join(root, ".bare")).GetConfig("remote.origin.url")
+	return registry.Update(config.GetRegistryPath(), func(r *registry.Registry) {
+		r.Add(registry.Project{Path: root, URL: url})
+	})
+}
+
+// summarizeProjects inspects every project in parallel, keeping their order
+func summarizeProjects(projects []registry.Project) []projectSummary {
+	summaries := make([]projectSummary, len(projects))
+	var wg sync.WaitGroup
+	for i, p := range projects {
+		wg.Add(1)
+		go func(i int, p registry.Project) {
+			defer wg.Done()
+			summaries[i] = summarizeProject(p)
+		}(i, p)
+	}
+	wg.Wait()
+	return summaries
+}
+
+// summarizeProject reads the local changes and upstream of each worktree in p
+func summarizeProject(p registry.Project) projectSummary {
+	summary := projectSummary{Project: p}
+	if info, err := os.Stat(filepath.Join(p.Path, ".bare")); err != nil || !info.IsDir() {
+		summary.Missing = true
+		return summary
+	}
+
+	worktrees, err := git.NewClient(p.Path).WorktreeListPorcelain()
+	if err != nil {
+		summary.Err = err
+		return summary
+	}
+
+	for _, wt := range worktrees {
+		if wt.Bare || wt.Prunable {
+			continue
+		}
+		client := git.NewClient(wt.Path)
+		state := worktreeState{Name: worktreeName(p.Path, wt.Path), Worktree: wt}
+
+		if state.Changes, err = client.ChangeCount(); err != nil {
+			summary.Err = err
+			return summary
+		}
+		if !wt.Detached {
+			if state.Upstream, err = client.BranchUpstream(wt.Branch); err != nil {
+				summary.Err = err
+				return summary
+			}
+		}
+		summary.Worktrees = append(summary.Worktrees, state)
+	}
+	return summary
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/registry"
+)
+
+func TestResolveProjectPath(t *testing.T) {
+	// setupProject records the project in the test's registry
+	root, branch := setupTestProject(t)
+
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "project", want: root},
+		{spec: "project/" + branch, want: filepath.Join(root, branch)},
+		{spec: "project/feat/missing", wantErr: true},
+		{spec: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := resolveProjectPath(config.GetRegistryPath(), tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveProjectPath(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveProjectPath(%q) = %q, want %q", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeProject(t *testing.T) {
+	root, branch := setupTestProject(t)
+	worktree := filepath.Join(root, branch)
+
+	// setup leaves the default branch without an upstream
+	if _, _, err := git.NewClient(worktree).ExecGit("branch", "--set-upstream-to=origin/"+branch); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, worktree, "committed.txt", "to push\n")
+	os.WriteFile(filepath.Join(worktree, "untracked.txt"), []byte("x\n"), 0644)
+
+	summary := summarizeProject(registry.Project{Name: "project", Path: root})
+	if summary.Err != nil || summary.Missing {
+		t.Fatalf("summarizeProject() = %+v, want a readable project", summary)
+	}
+	if len(summary.Worktrees) != 1 || summary.Dirty() != 1 {
+		t.Fatalf("summarizeProject() found %d worktree(s), %d dirty, want 1 and 1", len(summary.Worktrees), summary.Dirty())
+	}
+
+	wt := summary.Worktrees[0]
+	if wt.Name != branch || !wt.NeedsAttention() {
+		t.Errorf("worktree = %+v, want %s needing attention", wt, branch)
+	}
+	if got, want := describeWorktreeState(wt), "1 changed, 1 to push"; got != want {
+		t.Errorf("describeWorktreeState() = %q, want %q", got, want)
+	}
+
+	if gone := summarizeProject(registry.Project{Name: "gone", Path: filepath.Join(root, "gone")}); !gone.Missing {
+		t.Errorf("summarizeProject() of a deleted project = %+v, want Missing", gone)
+	}
+}
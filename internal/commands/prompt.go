@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// interactive reports whether gwtm may ask the user questions: not with
+// --no-input, and only when stdin is a terminal
+func interactive() bool {
+	return !noInput && ui.IsTerminal(os.Stdin)
+}
+
+// confirm answers a yes/no question. An explicit choice from a flag wins,
+// then --yes, then asking the user. When gwtm cannot ask, the question is an
+// error rather than a silent "no" — pair it with confirmGuidance.
+func confirm(emoji, question string, choice *bool) (bool, error) {
+	if choice != nil {
+		return *choice, nil
+	}
+	if assumeYes {
+		return true, nil
+	}
+	if !interactive() {
+		return false, fmt.Errorf("cannot ask %q: input is not interactive", question)
+	}
+	return ui.PromptYesNo(emoji+" "+question, os.Stdin)
+}
+
+// confirmGuidance is the guidance printed when confirm fails
+func confirmGuidance(flags string) string {
+	return "Pass " + flags + " (or --yes) to answer without a prompt"
+}
+
+// boolChoice returns the answer given by a --<name>/--no-<name> flag pair, or
+// nil when neither flag was passed
+func boolChoice(cmd *cobra.Command, name string) (*bool, error) {
+	yes := cmd.Flags().Changed(name)
+	no := cmd.Flags().Changed("no-" + name)
+	switch {
+	case yes && no:
+		return nil, fmt.Errorf("--%s and --no-%s cannot be used together", name, name)
+	case yes:
+		value, _ := cmd.Flags().GetBool(name)
+		return &value, nil
+	case no:
+		value, _ := cmd.Flags().GetBool("no-" + name)
+		value = !value
+		return &value, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestBoolChoice(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    *bool
+		wantErr bool
+	}{
+		{name: "neither flag", args: nil, want: nil},
+		{name: "positive flag", args: []string{"--push"}, want: boolPtr(true)},
+		{name: "negative flag", args: []string{"--no-push"}, want: boolPtr(false)},
+		{name: "explicit false", args: []string{"--push=false"}, want: boolPtr(false)},
+		{name: "both flags", args: []string{"--push", "--no-push"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			cmd.Flags().Bool("push", false, "")
+			cmd.Flags().Bool("no-push", false, "")
+			if err := cmd.Flags().Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := boolChoice(cmd, "push")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("boolChoice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("boolChoice() = %v, want %v", deref(got), deref(tt.want))
+			}
+		})
+	}
+}
+
+func TestConfirm_NonInteractive(t *testing.T) {
+	oldYes, oldNoInput := assumeYes, noInput
+	defer func() { assumeYes, noInput = oldYes, oldNoInput }()
+	noInput = true
+
+	// An explicit choice needs no prompt
+	if got, err := confirm("☁️", "Push?", boolPtr(false)); err != nil || got {
+		t.Errorf("confirm() with choice = %v, %v, want false, nil", got, err)
+	}
+
+	// Without a choice, a question that cannot be asked is an error
+	if _, err := confirm("☁️", "Push?", nil); err == nil {
+		t.Error("confirm() without choice in non-interactive mode error = nil, want error")
+	}
+
+	// --yes answers it
+	assumeYes = true
+	if got, err := confirm("☁️", "Push?", nil); err != nil || !got {
+		t.Errorf("confirm() with --yes = %v, %v, want true, nil", got, err)
+	}
+
+	// ...but an explicit choice still wins
+	if got, err := confirm("☁️", "Push?", boolPtr(false)); err != nil || got {
+		t.Errorf("confirm() with --yes and choice = %v, %v, want false, nil", got, err)
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func deref(b *bool) any {
+	if b == nil {
+		return nil
+	}
+	return *b
+}
@@ -1,19 +1,24 @@
 package commands
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/hooks"
 	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var removeCmd = &cobra.Command{
-	Use:   "remove <branch>",
+	Use:   "remove <branch|worktree>",
 	Short: "Remove worktree and branch",
-	Long:  "Remove a worktree and its associated local branch. Use --remote to also delete the remote branch.",
-	Args:  cobra.ExactArgs(1),
-	Run:   runRemove,
+	Long: `Remove a worktree and its associated local branch. Use --remote to also delete the remote branch.
+
+Detached worktrees created by 'gwtm checkout' have no branch, so only the directory is removed.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runRemove,
 }
 
 func init() {
@@ -22,7 +27,7 @@ func init() {
 }
 
 func runRemove(cmd *cobra.Command, args []string) {
-	branchName := args[0]
+	name := args[0]
 	removeRemote, _ := cmd.Flags().GetBool("remote")
 
 	root, err := findWorktreeRoot()
@@ -31,38 +36,103 @@ func runRemove(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	removeWorktree(root, name, removeRemote)
+}
+
+// removeWorktree removes the worktree for name (a branch or worktree
+// directory name) and its local branch, and the branch on origin too when
+// removeRemote is set. Hooks can veto the removal, and git refuses to remove
+// worktrees with local changes or branches that are not merged. Problems are
+// reported as they happen; the result says whether the worktree was removed.
+func removeWorktree(root, name string, removeRemote bool) bool {
 	client := git.NewClient(root)
 	client.DryRun = GetDryRun()
 
+	worktreePath := filepath.Join(root, worktreeDirName(name))
+
+	// The directory usually carries the branch name, but git knows for sure
+	branchName := name
+	wt, registered := findWorktree(root, worktreePath)
+	if registered {
+		branchName = wt.Branch
+	}
+	detached := registered && wt.Detached
+	if detached && removeRemote {
+		ui.PrintError(fmt.Errorf("worktree '%s' is detached and has no branch on origin", name), "Run again without --remote")
+		return false
+	}
+
+	env := hooks.Env{Branch: branchName, WorktreePath: worktreePath, ProjectRoot: root}
+
 	if client.DryRun {
-		ui.PrintDryRun("Would remove worktree '" + branchName + "'")
-		ui.PrintDryRun("Would delete local branch '" + branchName + "'")
+		if hasHooks(hooks.PreRemove, root, worktreePath) {
+			ui.PrintDryRun("Would run " + hooks.PreRemove + " hooks")
+		}
+		ui.PrintDryRun("Would remove worktree '" + name + "'")
+		if !detached {
+			ui.PrintDryRun("Would delete local branch '" + branchName + "'")
+		}
 		if removeRemote {
 			ui.PrintDryRun("Would delete remote branch 'origin/" + branchName + "'")
 		}
-		return
+		if hasHooks(hooks.PostRemove, root, mainWorktree(root, worktreePath)) {
+			ui.PrintDryRun("Would run " + hooks.PostRemove + " hooks")
+		}
+		return false
+	}
+
+	// A failing pre-remove hook vetoes the removal
+	if _, err := os.Stat(worktreePath); err == nil {
+		if err := runHooks(hooks.PreRemove, worktreePath, worktreePath, env, ui.PrintStatus); err != nil {
+			ui.PrintError(err, "Removal aborted — fix the problem or rerun with --no-hooks")
+			return false
+		}
 	}
 
-	worktreePath := filepath.Join(root, branchName)
-	ui.PrintStatus("🗑", "Removing worktree '"+branchName+"'")
+	// Capture where post-remove hooks come from before the worktree disappears
+	hooksFrom := mainWorktree(root, worktreePath)
+
+	ui.PrintStatus("🗑", "Removing worktree '"+name+"'")
 	if err := client.WorktreeRemove(worktreePath); err != nil {
 		ui.PrintError(err, "Use 'gwtm list' to see available worktrees")
-		return
+		return false
 	}
 
-	ui.PrintStatus("🧨", "Deleting local branch '"+branchName+"'")
-	if err := client.DeleteBranch(branchName, false); err != nil {
-		ui.PrintError(err, "Branch may have already been deleted")
-		// Continue anyway — worktree was already removed
+	if !detached {
+		ui.PrintStatus("🧨", "Deleting local branch '"+branchName+"'")
+		if err := client.DeleteBranch(branchName, false); err != nil {
+			ui.PrintError(err, "Branch may have already been deleted")
+			// Continue anyway — worktree was already removed
+		}
 	}
 
 	if removeRemote {
 		ui.PrintStatus("☁️", "Deleting remote branch 'origin/"+branchName+"'")
 		if err := client.DeleteRemoteBranch(branchName); err != nil {
 			ui.PrintError(err, "Remote branch may not exist or network issue")
-			return
+			// Continue anyway — worktree and local branch were already removed
 		}
 	}
 
+	// The worktree is gone, so post-remove hooks run from the project root
+	if err := runHooks(hooks.PostRemove, hooksFrom, root, env, ui.PrintStatus); err != nil {
+		ui.PrintError(err, "The worktree was removed, but the post-remove hook failed")
+	}
+
 	ui.PrintStatus("✅", "Removal complete.")
+	return true
+}
+
+// findWorktree returns the registered worktree at path
+func findWorktree(root, path string) (git.Worktree, bool) {
+	worktrees, err := git.NewClient(root).WorktreeListPorcelain()
+	if err != nil {
+		return git.Worktree{}, false
+	}
+	for _, wt := range worktrees {
+		if filepath.Clean(wt.Path) == filepath.Clean(path) || samePath(wt.Path, path) {
+			return wt, true
+		}
+	}
+	return git.Worktree{}, false
 }
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/steps"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <old-branch> <new-branch>",
+	Short: "Rename a branch and its worktree",
+	Long: `Rename a branch and move its worktree directory to match.
+
+With --remote, also push the branch under its new name, point its upstream there,
+and delete the old name from origin. If a step fails, the steps already done are undone.`,
+	Args: cobra.ExactArgs(2),
+	Run:  runRename,
+}
+
+func init() {
+	renameCmd.Flags().Bool("remote", false, "Also rename the branch on origin")
+	rootCmd.AddCommand(renameCmd)
+}
+
+func runRename(cmd *cobra.Command, args []string) {
+	oldName, newName := args[0], args[1]
+	remote, _ := cmd.Flags().GetBool("remote")
+
+	root, err := findWorktreeRoot()
+	if err != nil {
+		ui.PrintError(err, "Run this command from within a worktree-managed repository")
+		return
+	}
+
+	policy, err := loadNamingPolicy(root)
+	if err != nil {
+		ui.PrintError(err, "Fix the branch.* settings with 'gwtm config set' (--project for this project only); 'gwtm config list --show-origin' shows where they come from")
+		return
+	}
+	if err := git.NewClient(root).CheckBranchName(newName); err != nil {
+		ui.PrintError(err, "Branch names can't contain spaces, '..', '~', '^', ':' or end in '.lock'")
+		return
+	}
+	if err := policy.validate(newName); err != nil {
+		ui.PrintError(err, policy.describe())
+		return
+	}
+
+	oldPath := filepath.Join(root, worktreeDirName(oldName))
+	newPath := filepath.Join(root, worktreeDirName(newName))
+
+	if wt, ok := findWorktree(root, oldPath); !ok || wt.Branch != oldName {
+		ui.PrintError(fmt.Errorf("no worktree for branch %q", oldName), "Use 'gwtm list' to see available worktrees")
+		return
+	}
+	if git.NewClient(root).BranchExists(newName, false) {
+		ui.PrintError(fmt.Errorf("branch %q already exists", newName), "Choose another name")
+		return
+	}
+	if newPath != oldPath {
+		if err := checkWorktreeDir(root, newPath, newName); err != nil {
+			ui.PrintError(err, "Choose another name or move that directory away")
+			return
+		}
+	}
+
+	client := git.NewClient(root)
+	client.DryRun = GetDryRun()
+
+	if client.DryRun {
+		ui.PrintDryRun(fmt.Sprintf("Would rename branch '%s' to '%s'", oldName, newName))
+		if newPath != oldPath {
+			ui.PrintDryRun(fmt.Sprintf("Would move worktree %s to %s", oldPath, newPath))
+		}
+		if remote {
+			ui.PrintDryRun("Would push '" + newName + "' to origin and track it")
+			ui.PrintDryRun("Would delete 'origin/" + oldName + "'")
+		}
+		return
+	}
+
+	if err := renameBranchWorktree(client, oldName, newName, oldPath, newPath, remote); err != nil {
+		printGuidedError(err, "Nothing was changed — fix the problem and try again")
+		return
+	}
+
+	ui.PrintStatus("✅", "Renamed '"+oldName+"' to '"+newName+"' — worktree is at: "+newPath)
+}
+
+// renameBranchWorktree renames the branch oldName and moves its worktree from
+// oldPath to newPath, optionally renaming it on origin too. If a step fails,
+// the completed steps are undone in reverse order and a summary is reported.
+func renameBranchWorktree(client *git.Client, oldName, newName, oldPath, newPath string, remote bool) error {
+	runner := steps.NewRunner(ui.PrintStatus)
+	fail := func(err error) error {
+		runner.PrintSummary()
+		return err
+	}
+
+	err := runner.Run(steps.Step{
+		Emoji: "🏷️",
+		Name:  fmt.Sprintf("Renaming branch '%s' to '%s'", oldName, newName),
+		Do:    func() error { return client.RenameBranch(oldName, newName) },
+		Undo:  func() error { return client.RenameBranch(newName, oldName) },
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	if newPath != oldPath {
+		err := runner.Run(steps.Step{
+			Emoji: "📦",
+			Name:  "Moving worktree to " + newPath,
+			Do:    func() error { return client.WorktreeMove(oldPath, newPath) },
+			Undo:  func() error { return client.WorktreeMove(newPath, oldPath) },
+		})
+		if err != nil {
+			return fail(withGuidance(err, "The branch rename was undone — close anything using the worktree and try again"))
+		}
+	}
+
+	// The renamed branch still tracks the old remote name until it is pushed
+	mergeKey := "branch." + newName + ".merge"
+	upstream, _ := client.GetConfig(mergeKey)
+
+	if !remote {
+		if upstream != "" {
+			ui.PrintStatus("💡", "'"+newName+"' still tracks its old upstream — use --remote to rename it on origin too")
+		}
+		return nil
+	}
+
+	oldOnRemote, err := client.RemoteBranches("origin", oldName)
+	if err != nil {
+		runner.Rollback()
+		return fail(withGuidance(err, "The rename was undone — check your network connection and try again"))
+	}
+
+	err = runner.Run(steps.Step{
+		Emoji: "☁️",
+		Name:  "Pushing '" + newName + "' to origin",
+		Do:    func() error { return client.Push(newName, true) },
+		Undo: func() error {
+			if upstream != "" {
+				if err := client.SetConfig(mergeKey, upstream); err != nil {
+					return err
+				}
+			}
+			return client.DeleteRemoteBranch(newName)
+		},
+	})
+	if err != nil {
+		return fail(withGuidance(err, "The rename was undone — check your network connection and try again"))
+	}
+
+	if len(oldOnRemote) == 0 {
+		return nil
+	}
+
+	// Last step: if it fails, both names exist on origin, which is safe to leave
+	err = runner.Run(steps.Step{
+		Emoji:    "🧨",
+		Name:     "Deleting 'origin/" + oldName + "'",
+		Do:       func() error { return client.DeleteRemoteBranch(oldName) },
+		Optional: true,
+	})
+	if err != nil {
+		runner.PrintSummary()
+		ui.PrintError(err, "The rename succeeded; delete the old branch with 'git push origin --delete "+oldName+"'")
+	}
+
+	return nil
+}
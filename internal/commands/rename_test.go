@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+)
+
+// addTestWorktree creates branch name from branch base and a worktree for it
+func addTestWorktree(t *testing.T, root, name, base string) string {
+	t.Helper()
+	client := git.NewClient(root)
+	if err := client.CreateBranch(name, base); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(root, worktreeDirName(name))
+	if err := client.WorktreeAdd(path, name, false); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRenameBranchWorktree(t *testing.T) {
+	root, branch := setupTestProject(t)
+	client := git.NewClient(root)
+	oldPath := addTestWorktree(t, root, "feature-old", branch)
+	if err := client.Push("feature-old", true); err != nil {
+		t.Fatal(err)
+	}
+
+	newPath := filepath.Join(root, "feat-new")
+	if err := renameBranchWorktree(client, "feature-old", "feat/new", oldPath, newPath, true); err != nil {
+		t.Fatalf("renameBranchWorktree() error = %v", err)
+	}
+
+	if client.BranchExists("feature-old", false) || !client.BranchExists("feat/new", false) {
+		t.Error("renameBranchWorktree() did not rename the local branch")
+	}
+	if _, err := os.Stat(filepath.Join(newPath, "README.md")); err != nil {
+		t.Errorf("renameBranchWorktree() did not move the worktree: %v", err)
+	}
+	if wt, ok := findWorktree(root, newPath); !ok || wt.Branch != "feat/new" {
+		t.Errorf("worktree at %s = %+v, want branch feat/new", newPath, wt)
+	}
+
+	remotes, err := client.RemoteBranches("origin", "feature-old", "feat/new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remotes) != 1 || remotes[0] != "feat/new" {
+		t.Errorf("branches on origin = %v, want only feat/new", remotes)
+	}
+	if merge, _ := client.GetConfig("branch.feat/new.merge"); merge != "refs/heads/feat/new" {
+		t.Errorf("upstream = %q, want refs/heads/feat/new", merge)
+	}
+}
+
+func TestRenameBranchWorktree_RollsBack(t *testing.T) {
+	root, branch := setupTestProject(t)
+	client := git.NewClient(root)
+	oldPath := addTestWorktree(t, root, "feature-old", branch)
+
+	// A locked worktree can't be moved, so the rename must be undone
+	client.ExecGit("worktree", "lock", oldPath)
+
+	err := renameBranchWorktree(client, "feature-old", "feature-new", oldPath, filepath.Join(root, "feature-new"), false)
+	if err == nil {
+		t.Fatal("renameBranchWorktree() with locked worktree error = nil, want error")
+	}
+
+	if !client.BranchExists("feature-old", false) || client.BranchExists("feature-new", false) {
+		t.Error("renameBranchWorktree() did not roll back the branch rename")
+	}
+	if wt, ok := findWorktree(root, oldPath); !ok || wt.Branch != "feature-old" {
+		t.Errorf("worktree at %s = %+v, want branch feature-old", oldPath, wt)
+	}
+}
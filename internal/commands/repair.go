@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Repair worktree links after moving the project",
+	Long: `Rewrite the links between .bare and its worktrees after the project
+directory has been moved or copied to a new location.
+
+When git supports it (2.48+), links are rewritten as relative paths and the
+repository is configured to keep using relative paths, so later moves do not
+break anything.`,
+	Run: runRepair,
+}
+
+func init() {
+	rootCmd.AddCommand(repairCmd)
+}
+
+func runRepair(cmd *cobra.Command, args []string) {
+	root, err := findProjectRoot()
+	if err != nil {
+		ui.PrintError(err, "Run this command from the moved project's root directory")
+		return
+	}
+
+	bare := git.NewClient(filepath.Join(root, ".bare"))
+	bare.DryRun = GetDryRun()
+
+	broken, err := brokenWorktreeLinks(root)
+	if err != nil {
+		ui.PrintError(err, "Failed to inspect worktree links")
+		return
+	}
+
+	relative := bare.SupportsRelativeWorktreePaths()
+	if relative {
+		current, _ := git.NewClient(bare.WorkDir).GetConfig("worktree.useRelativePaths")
+		relative = current != "true"
+	}
+
+	if len(broken) == 0 && !relative {
+		ui.PrintStatus("✅", "All worktree links are valid.")
+		return
+	}
+
+	if bare.DryRun {
+		for _, dir := range broken {
+			ui.PrintDryRun("Would repair links for " + dir)
+		}
+		if relative {
+			ui.PrintDryRun("Would switch worktree links to relative paths")
+		}
+		return
+	}
+
+	for _, dir := range broken {
+		ui.PrintStatus("🔗", "Repairing links for "+dir)
+	}
+	if relative {
+		ui.PrintStatus("🔗", "Switching worktree links to relative paths")
+	}
+
+	if err := repairWorktreeLinks(root, bare); err != nil {
+		ui.PrintError(err, "Run 'gwtm doctor' for details")
+		return
+	}
+
+	ui.PrintStatus("✅", fmt.Sprintf("Repair complete. %d worktree(s) relinked.", len(broken)))
+}
+
+// repairWorktreeLinks re-establishes the links between .bare and every
+// worktree directory under root, using relative paths when git supports them.
+func repairWorktreeLinks(root string, bare *git.Client) error {
+	// The root .git file is written relative by setup, but may have been
+	// hand-edited to an absolute path
+	if target, err := readGitdirFile(root); err != nil || !samePath(target, bare.WorkDir) {
+		if err := os.WriteFile(filepath.Join(root, ".git"), []byte("gitdir: ./.bare"), 0644); err != nil {
+			return fmt.Errorf("failed to rewrite .git file: %w", err)
+		}
+	}
+
+	dirs, err := worktreeDirs(root)
+	if err != nil {
+		return err
+	}
+
+	relative := bare.SupportsRelativeWorktreePaths()
+	if err := bare.WorktreeRepair(relative, dirs...); err != nil {
+		return err
+	}
+	if relative {
+		if err := bare.SetConfig("worktree.useRelativePaths", "true"); err != nil {
+			return err
+		}
+	}
+
+	remaining, err := brokenWorktreeLinks(root)
+	if err != nil {
+		return err
+	}
+	if len(remaining) > 0 {
+		return fmt.Errorf("links could not be repaired for: %s", strings.Join(remaining, ", "))
+	}
+
+	return nil
+}
+
+// brokenWorktreeLinks returns the worktree directories under root whose .git
+// file and matching .bare/worktrees/<name>/gitdir entry do not point at each other.
+func brokenWorktreeLinks(root string) ([]string, error) {
+	dirs, err := worktreeDirs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var broken []string
+	for _, dir := range dirs {
+		if !worktreeLinkValid(dir) {
+			broken = append(broken, dir)
+		}
+	}
+
+	return broken, nil
+}
+
+// worktreeLinkValid reports whether dir/.git points at an admin directory
+// whose gitdir file points back at dir/.git
+func worktreeLinkValid(dir string) bool {
+	adminDir, err := readGitdirFile(dir)
+	if err != nil {
+		return false
+	}
+
+	data, err := os.ReadFile(filepath.Join(adminDir, "gitdir"))
+	if err != nil {
+		return false
+	}
+
+	back := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(back) {
+		// Relative links are relative to the admin directory
+		back = filepath.Join(adminDir, back)
+	}
+
+	return samePath(back, filepath.Join(dir, ".git"))
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+)
+
+func TestRepairWorktreeLinks_AfterMove(t *testing.T) {
+	root, branch := setupTestProject(t)
+
+	broken, err := brokenWorktreeLinks(root)
+	if err != nil {
+		t.Fatalf("brokenWorktreeLinks() error = %v", err)
+	}
+	if len(broken) != 0 {
+		t.Fatalf("brokenWorktreeLinks() on a fresh project = %v, want none", broken)
+	}
+
+	// Move the whole project, as when relocating it to a new disk
+	moved := filepath.Join(filepath.Dir(root), "moved")
+	if err := os.Rename(root, moved); err != nil {
+		t.Fatalf("failed to move project: %v", err)
+	}
+
+	broken, err = brokenWorktreeLinks(moved)
+	if err != nil {
+		t.Fatalf("brokenWorktreeLinks() after move error = %v", err)
+	}
+	if len(broken) != 1 || filepath.Base(broken[0]) != branch {
+		t.Fatalf("brokenWorktreeLinks() after move = %v, want [%s]", broken, branch)
+	}
+
+	bare := git.NewClient(filepath.Join(moved, ".bare"))
+	if err := repairWorktreeLinks(moved, bare); err != nil {
+		t.Fatalf("repairWorktreeLinks() error = %v", err)
+	}
+
+	broken, err = brokenWorktreeLinks(moved)
+	if err != nil {
+		t.Fatalf("brokenWorktreeLinks() after repair error = %v", err)
+	}
+	if len(broken) != 0 {
+		t.Errorf("brokenWorktreeLinks() after repair = %v, want none", broken)
+	}
+
+	// The worktree is usable and no longer considered prunable
+	if _, _, err := git.NewClient(filepath.Join(moved, branch)).ExecGit("status"); err != nil {
+		t.Errorf("git status in repaired worktree error = %v", err)
+	}
+	worktrees, _ := bare.WorktreeListPorcelain()
+	for _, wt := range worktrees {
+		if wt.Prunable {
+			t.Errorf("worktree %s still prunable after repair", wt.Path)
+		}
+	}
+}
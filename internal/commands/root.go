@@ -3,12 +3,18 @@ package commands
 import (
 	"fmt"
 
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Global flags
-	dryRun bool
+	dryRun     bool
+	noHooks    bool
+	trustHooks bool
+	assumeYes  bool
+	noInput    bool
 
 	// Build info — set via SetBuildInfo from main
 	appVersion string
@@ -22,21 +28,43 @@ var rootCmd = &cobra.Command{
 	Long: `🛠 Git Worktree Manager — A tool to simplify git worktree management
 
 Supports:
-  - Full repository setup from GitHub
+  - Full repository setup from GitHub, GitLab or any Git host, file:// URLs
+    and local paths, or a manifest listing many repositories
   - Branch and worktree creation
   - Worktree listing and removal
   - Version management and self-upgrade
   - Dry-run mode for all destructive operations`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Completion output is parsed by the shell, so leave it alone
+		if cmd.Name() != cobra.ShellCompRequestCmd && cmd.Name() != cobra.ShellCompNoDescRequestCmd {
+			migrateLegacyDirs()
+		}
+	},
 }
 
 func init() {
 	// Global persistent flags
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Preview actions without executing")
+	rootCmd.PersistentFlags().BoolVar(&noHooks, "no-hooks", false, "Skip lifecycle hooks")
+	rootCmd.PersistentFlags().BoolVar(&trustHooks, "trust-hooks", false, "Run the repository's own hooks without asking")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Answer yes to every question")
+	rootCmd.PersistentFlags().BoolVar(&noInput, "no-input", false, "Never prompt; fail when a question has no answer from flags (implied when stdin is not a terminal)")
 }
 
-// Execute runs the root command
+// Execute runs the root command. Commands report their own errors, so it
+// also fails when any error was printed, giving scripts a non-zero exit status.
 func Execute() error {
-	return rootCmd.Execute()
+	registerAliases()
+	if err := rootCmd.Execute(); err != nil {
+		return err
+	}
+	if n := ui.ErrorCount(); n > 0 {
+		return fmt.Errorf("%d error(s) reported", n)
+	}
+	if aliasStepFailed {
+		return fmt.Errorf("alias step failed")
+	}
+	return nil
 }
 
 // SetBuildInfo sets the version, commit hash, and build date (called from main).
@@ -71,3 +99,34 @@ func GetDate() string {
 func GetDryRun() bool {
 	return dryRun
 }
+
+// GetNoHooks returns the no-hooks flag value
+func GetNoHooks() bool {
+	return noHooks
+}
+
+// GetTrustHooks returns the trust-hooks flag value
+func GetTrustHooks() bool {
+	return trustHooks
+}
+
+// migrateLegacyDirs moves files gwtm kept in the install directory to their
+// XDG base directories, reporting each move
+func migrateLegacyDirs() {
+	if GetDryRun() {
+		for _, m := range config.LegacyMoves() {
+			ui.PrintDryRun("Would move " + m.From + " to " + m.To)
+		}
+		return
+	}
+
+	moved, err := config.MigrateLegacyDirs()
+	for _, m := range moved {
+		ui.PrintStatus("📦", "Moved "+m.From+" to "+m.To)
+	}
+	// A failed move leaves the old file in place, so warn rather than fail the command
+	if err != nil {
+		ui.PrintStatus("⚠️", "Could not move gwtm's files to the XDG base directories: "+err.Error())
+		ui.PrintStatus("💡", "Move them by hand, or set GIT_WORKTREE_MANAGER_HOME to keep everything in "+config.GetInstallDir())
+	}
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// gwtm settings are layered by internal/config: defaults, the user's
+// config.toml, gwtm.* in global git config, the project's .gwtm.toml, gwtm.*
+// in the project's git config, GWTM_* environment variables and flags.
+
+// settingsWarning makes sure unreadable settings are reported once per run
+var settingsWarning sync.Once
+
+// loadSettings returns the settings that apply in the project containing dir,
+// or outside any project when dir is empty. A layer that cannot be read is
+// reported and skipped.
+func loadSettings(dir string) *config.Settings {
+	settings, err := config.Load(settingsRoot(dir))
+	if err != nil {
+		settingsWarning.Do(func() {
+			ui.PrintStatus("⚠️", "Ignoring unreadable settings: "+err.Error())
+		})
+	}
+	return settings
+}
+
+// settingsRoot returns the project directory holding .bare at or above dir,
+// so worktrees share their project's settings; empty when there is none
+func settingsRoot(dir string) string {
+	for dir != "" {
+		if info, err := os.Stat(filepath.Join(dir, ".bare")); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return ""
+}
+
+// getSetting returns the setting key that applies in dir
+func getSetting(dir, key string) string {
+	return loadSettings(dir).Get(key)
+}
+
+// getBoolSetting returns the boolean setting key that applies in dir
+func getBoolSetting(dir, key string) bool {
+	return loadSettings(dir).Bool(key)
+}
+
+// applyFlags layers the flags that were passed over settings; flags maps
+// each flag name to the setting it overrides
+func applyFlags(cmd *cobra.Command, settings *config.Settings, flags map[string]string) {
+	for flag, key := range flags {
+		if cmd.Flags().Changed(flag) {
+			settings.SetFlag(key, "--"+flag, cmd.Flags().Lookup(flag).Value.String())
+		}
+	}
+}
+
+// gitProfile returns the name and git settings of the profile name, or of the
+// configured profile when name is empty
+func gitProfile(settings *config.Settings, name string) (string, map[string]string, error) {
+	if name == "" {
+		name = settings.Get("profile")
+	}
+	profile, err := settings.Profile(name)
+	if err != nil {
+		return name, nil, withGuidance(err, "Define it with 'gwtm config set profiles."+name+".<git key> <value>' or choose another profile")
+	}
+	return name, profile, nil
+}
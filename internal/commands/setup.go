@@ -1,35 +1,105 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	neturl "net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
 	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/hooks"
+	"github.com/lucasmodrich/git-worktree-manager/internal/steps"
 	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var setupCmd = &cobra.Command{
-	Use:   "setup <org>/<repo>",
+	Use:   "setup <org>/<repo>|<url>",
 	Short: "Full repository setup",
-	Long:  "Clone a repository as a bare repo and create initial worktree for the default branch",
-	Args:  cobra.ExactArgs(1),
-	Run:   runSetup,
+	Long: `Clone a repository as a bare repo and create initial worktree for the default branch.
+
+With --manifest, set up every repository listed in a YAML manifest instead:
+
+  repos:
+    - url: acme/webapp
+      dir: clients/webapp
+      branch: develop
+
+Repositories whose directory already exists are skipped, so reruns are safe.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if manifest, _ := cmd.Flags().GetString("manifest"); manifest != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: runSetup,
 }
 
 func init() {
+	setupCmd.Flags().String("manifest", "", "Set up every repository listed in a manifest file")
+	setupCmd.Flags().Int("jobs", 4, "Maximum number of repositories set up in parallel (with --manifest)")
+	setupCmd.Flags().String("reference", "", "Borrow objects from a local repository instead of downloading them")
+	setupCmd.Flags().Bool("cache", false, "Share objects through gwtm's object cache (default from gwtm.referenceCache)")
+	setupCmd.Flags().Bool("submodules", false, "Initialise submodules in the initial worktree (default from gwtm.submodules)")
+	setupCmd.Flags().Bool("maintenance", false, "Register the repository for scheduled background maintenance (default from gwtm.maintenance)")
+	setupCmd.Flags().String("profile", "", "Profile of git settings to apply and remember for the project (default from gwtm.profile)")
 	rootCmd.AddCommand(setupCmd)
 }
 
+// setupOptions customises a single project setup
+type setupOptions struct {
+	Branch      string            // Branch for the initial worktree; the default branch when empty
+	Config      map[string]string // Extra git config applied to the bare repository
+	Reference   string            // Local repository to borrow objects from
+	UseCache    bool              // Borrow objects from the shared cache, creating or updating it first
+	Submodules  bool              // Initialise submodules in the initial worktree
+	Maintenance bool              // Register the bare repository with 'git maintenance start'
+	Profile     string            // Profile of git settings, recorded as the project's gwtm.profile; the configured profile when empty
+	Settings    *config.Settings  // Settings the caller resolved, flags included; profiles are read from them
+}
+
+// cacheMu serialises updates to the shared object cache when several projects
+// are set up in parallel
+var cacheMu sync.Mutex
+
 func runSetup(cmd *cobra.Command, args []string) {
+	reference, _ := cmd.Flags().GetString("reference")
+	settings := loadSettings("")
+	applyFlags(cmd, settings, map[string]string{"cache": "referenceCache", "submodules": "submodules", "maintenance": "maintenance"})
+	useCache := settings.Bool("referenceCache")
+	submodules := settings.Bool("submodules")
+	maintenance := settings.Bool("maintenance")
+	profile, _ := cmd.Flags().GetString("profile")
+	profileName, _, err := gitProfile(settings, profile)
+	if err != nil {
+		printGuidedError(err, "Choose a known profile")
+		return
+	}
+	if reference != "" {
+		expanded, err := expandLocalPath(reference)
+		if err != nil {
+			ui.PrintError(err, "--reference must be a local repository path")
+			return
+		}
+		reference = expanded
+	}
+
+	if manifest, _ := cmd.Flags().GetString("manifest"); manifest != "" {
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		runBootstrap(manifest, jobs, setupOptions{Reference: reference, UseCache: useCache, Submodules: submodules, Maintenance: maintenance, Profile: profile, Settings: settings})
+		return
+	}
+
 	repoSpec := args[0]
 
-	url, repoName, err := parseRepoSpec(repoSpec)
+	url, repoName, err := parseRepoSpec(repoSpec, repoSpecOptionsFrom(settings))
 	if err != nil {
-		ui.PrintError(err, "Examples: acme/webapp, git@gitlab.com:org/repo.git, https://github.com/org/repo")
+		ui.PrintError(err, "Examples: acme/webapp, gl:group/sub/repo, git@gitlab.com:org/repo.git, https://github.com/org/repo, ./local/repo.git")
 		return
 	}
 
@@ -50,130 +120,422 @@ func runSetup(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	// All git operations use repoDir as the working directory
-	client := git.NewClient(repoDir)
-	client.DryRun = GetDryRun()
-
-	if client.DryRun {
+	if GetDryRun() {
 		ui.PrintDryRun("Would create project root: " + repoDir)
+		if useCache && !isLocalPath(url) {
+			ui.PrintDryRun("Would update shared object cache: " + referenceCachePath(url))
+		}
 		ui.PrintDryRun("Would clone bare repository into .bare")
 		ui.PrintDryRun("Would create .git file pointing to .bare")
-		ui.PrintDryRun("Would configure Git for auto remote tracking")
+		ui.PrintDryRun("Would apply the " + profileName + " profile of git settings")
 		ui.PrintDryRun("Would fetch all remote branches")
+		ui.PrintDryRun("Would record the remote's default branch")
 		ui.PrintDryRun("Would create initial worktree for default branch")
+		if submodules {
+			ui.PrintDryRun("Would initialise submodules in the initial worktree")
+		}
+		if hasHooks(hooks.PostSetup, "", repoDir) {
+			ui.PrintDryRun("Would run " + hooks.PostSetup + " hooks in the initial worktree")
+		}
+		if maintenance {
+			ui.PrintDryRun("Would register the repository for scheduled background maintenance")
+		}
+		ui.PrintDryRun("Would record the project in the registry")
 		return
 	}
 
-	// Create project root directory
-	ui.PrintStatus("📂", "Creating project root: "+repoName)
-	if err := os.MkdirAll(repoDir, 0755); err != nil {
-		ui.PrintError(err, "Failed to create project directory")
-		return
+	opts := setupOptions{Reference: reference, UseCache: useCache, Submodules: submodules, Maintenance: maintenance, Profile: profile, Settings: settings}
+	branch, err := setupProject(url, repoDir, opts, ui.PrintStatus)
+	if err != nil {
+		printGuidedError(err, "Setup failed")
+		if branch == "" {
+			return
+		}
+	}
+
+	ui.PrintStatus("✅", fmt.Sprintf("Setup complete! cd %s/%s to start working.", repoName, worktreeDirName(branch)))
+}
+
+// setupProject clones url as a bare repository into repoDir and creates the
+// initial worktree, reporting progress through status. It returns the branch
+// checked out in the initial worktree. If any step fails, repoDir is removed,
+// a summary is reported and the returned error carries guidance for the user.
+// Failures once the worktree exists, in submodules or hooks, leave the project
+// in place: the branch is returned along with the error. Errors are left to
+// the caller to print, since bootstrap runs several setups at once.
+func setupProject(url, repoDir string, opts setupOptions, status func(emoji, message string)) (string, error) {
+	// All git operations use repoDir as the working directory
+	client := git.NewClient(repoDir)
+	runner := steps.NewRunner(status)
+	fail := func(err error) (string, error) {
+		runner.PrintSummary()
+		return "", err
+	}
+
+	profileName, profile, err := gitProfile(opts.Settings, opts.Profile)
+	if err != nil {
+		return "", err
 	}
 
-	// Remove the partially-created directory if any subsequent step fails
-	cleanup := true
-	defer func() {
-		if cleanup {
-			os.RemoveAll(repoDir)
+	// Removing the project root undoes every later step, so it is the only
+	// step that needs an Undo
+	err = runner.Run(steps.Step{
+		Emoji: "📂",
+		Name:  "Creating project root: " + filepath.Base(repoDir),
+		Do: func() error {
+			if err := os.MkdirAll(repoDir, 0755); err != nil {
+				return withGuidance(err, "Failed to create project directory")
+			}
+			return nil
+		},
+		Undo: func() error { return os.RemoveAll(repoDir) },
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	reference := opts.Reference
+	// Clones from plain local paths already hardlink objects, so skip the cache
+	if opts.UseCache && reference == "" && !isLocalPath(url) {
+		cachePath := referenceCachePath(url)
+		status("🗄️", "Updating shared object cache")
+		cacheMu.Lock()
+		err := client.SyncMirror(url, cachePath)
+		cacheMu.Unlock()
+		if err != nil {
+			// The cache only saves time and space — fall back to a plain clone
+			status("⚠️", "Shared object cache unavailable, cloning without it")
+		} else {
+			reference = cachePath
 		}
-	}()
+	}
 
 	bareDir := filepath.Join(repoDir, ".bare")
 
-	ui.PrintStatus("📦", "Cloning bare repository into .bare")
-	if err := client.Clone(url, bareDir, true); err != nil {
-		ui.PrintError(err, "Check network connection and verify repository URL is accessible")
-		return
+	err = runner.Run(steps.Step{
+		Emoji: "📦",
+		Name:  "Cloning bare repository into .bare",
+		Do: func() error {
+			if err := client.CloneWithReference(url, bareDir, reference, true); err != nil {
+				return withGuidance(err, "Check network connection and verify repository URL is accessible")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return fail(err)
 	}
 
-	ui.PrintStatus("📝", "Creating .git file pointing to .bare")
-	gitFile := filepath.Join(repoDir, ".git")
-	if err := os.WriteFile(gitFile, []byte("gitdir: ./.bare"), 0644); err != nil {
-		ui.PrintError(err, "Failed to create .git file")
-		return
+	err = runner.Run(steps.Step{
+		Emoji: "📝",
+		Name:  "Creating .git file pointing to .bare",
+		Do: func() error {
+			gitFile := filepath.Join(repoDir, ".git")
+			if err := os.WriteFile(gitFile, []byte("gitdir: ./.bare"), 0644); err != nil {
+				return withGuidance(err, "Failed to create .git file")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return fail(err)
 	}
 
-	ui.PrintStatus("⚙️", "Configuring Git for auto remote tracking")
-	if err := client.ConfigureWorktreeSettings(); err != nil {
-		ui.PrintError(err, "Failed to configure git settings")
-		return
+	err = runner.Run(steps.Step{
+		Emoji: "⚙️",
+		Name:  "Applying the " + profileName + " profile of git settings",
+		Do: func() error {
+			if _, err := client.ApplyConfig(profile); err != nil {
+				return withGuidance(err, "Failed to configure git settings")
+			}
+			// Projects set up with a chosen profile keep it for 'gwtm config apply'
+			if opts.Profile != "" {
+				if err := client.SetConfig("gwtm.profile", opts.Profile); err != nil {
+					return withGuidance(err, "Failed to configure git settings")
+				}
+			}
+			for key, value := range opts.Config {
+				if err := client.SetConfig(key, value); err != nil {
+					return withGuidance(err, "Check the git config settings for this repository")
+				}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return fail(err)
 	}
 
-	ui.PrintStatus("🔧", "Ensuring all remote branches are fetched")
-	if err := client.ConfigureFetchRefspec(); err != nil {
-		ui.PrintError(err, "Failed to configure fetch refspec")
-		return
+	err = runner.Run(steps.Step{
+		Emoji: "🔧",
+		Name:  "Ensuring all remote branches are fetched",
+		Do: func() error {
+			if err := client.ConfigureFetchRefspec(); err != nil {
+				return withGuidance(err, "Failed to configure fetch refspec")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return fail(err)
 	}
 
-	ui.PrintStatus("📡", "Fetching all remote branches")
-	if err := client.Fetch(true, false); err != nil {
-		ui.PrintError(err, "Failed to fetch remote branches")
-		return
+	err = runner.Run(steps.Step{
+		Emoji: "📡",
+		Name:  "Fetching all remote branches",
+		Do: func() error {
+			if err := client.Fetch(true, false); err != nil {
+				return withGuidance(err, "Failed to fetch remote branches")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return fail(err)
 	}
 
-	defaultBranch, err := client.DetectDefaultBranch()
+	// A bare clone does not record origin/HEAD; set it so the default branch
+	// can later be detected without contacting the remote
+	if err := client.SetRemoteHead(); err != nil {
+		status("⚠️", "Could not record the remote's default branch — run 'gwtm doctor --fix' later")
+	}
+
+	branch := opts.Branch
+	if branch == "" {
+		defaultBranch, err := client.DetectDefaultBranch()
+		if err != nil {
+			runner.Rollback()
+			return fail(withGuidance(err, "Could not detect default branch"))
+		}
+		branch = defaultBranch
+	}
+
+	worktreePath := filepath.Join(repoDir, worktreeDirName(branch))
+	err = runner.Run(steps.Step{
+		Emoji: "🌱",
+		Name:  "Creating initial worktree for branch: " + branch,
+		Do: func() error {
+			if err := client.WorktreeAdd(worktreePath, branch, false); err != nil {
+				return withGuidance(err, "Failed to create worktree for branch "+branch)
+			}
+			return nil
+		},
+	})
 	if err != nil {
-		ui.PrintError(err, "Could not detect default branch")
-		return
+		return fail(err)
 	}
 
-	ui.PrintStatus("🌱", "Creating initial worktree for branch: "+defaultBranch)
-	worktreePath := filepath.Join(repoDir, defaultBranch)
-	if err := client.WorktreeAdd(worktreePath, defaultBranch, false); err != nil {
-		ui.PrintError(err, "Failed to create worktree for default branch")
-		return
+	// Submodule failures leave a usable worktree, so they don't undo the setup
+	var problems []error
+	if opts.Submodules {
+		if err := initSubmodules(worktreePath, "", status); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	// Maintenance only keeps the repository fast, so it doesn't undo the setup either
+	if opts.Maintenance {
+		if err := scheduleMaintenance(git.NewClient(filepath.Join(repoDir, ".bare")), status); err != nil {
+			status("⚠️", "Could not schedule maintenance — run 'gwtm maintain --schedule' later")
+		}
+	}
+
+	if err := registerProject(repoDir); err != nil {
+		status("⚠️", "Could not record the project in the registry — run 'gwtm projects add' later")
+	}
+
+	env := hooks.Env{Branch: branch, WorktreePath: worktreePath, ProjectRoot: repoDir}
+	if err := runHooks(hooks.PostSetup, worktreePath, worktreePath, env, status); err != nil {
+		problems = append(problems, withGuidance(err, "Fix the hook and rerun it manually in "+worktreePath))
 	}
+	return branch, errors.Join(problems...)
+}
 
-	cleanup = false // all steps succeeded — keep the directory
-	ui.PrintStatus("✅", fmt.Sprintf("Setup complete! cd %s/%s to start working.", repoName, defaultBranch))
+// repoSpecOptions controls how shorthand repository specs are expanded into
+// clone URLs.
+type repoSpecOptions struct {
+	DefaultHost string            // Host used for the org/repo shorthand
+	Protocol    string            // "ssh" or "https"
+	Hosts       map[string]string // Alias → host or URL prefix, used as <alias>:<path>
+}
+
+// repoSpecOptionsFrom reads the expansion rules from settings:
+//   - defaultHost  host for org/repo shorthand (default github.com)
+//   - protocol     ssh or https (default ssh)
+//   - host.<alias> host or URL prefix for <alias>:<path> shorthand
+func repoSpecOptionsFrom(settings *config.Settings) repoSpecOptions {
+	return repoSpecOptions{
+		DefaultHost: settings.Get("defaultHost"),
+		Protocol:    settings.Get("protocol"),
+		Hosts:       settings.Family("host"),
+	}
 }
 
 // parseRepoSpec accepts the following formats and returns the clone URL and repo name:
-//   - org/repo            → expanded to git@github.com:org/repo.git
-//   - git@<host>:<path>   → used as-is (any SSH host)
-//   - https?://<host>/... → used as-is
+//   - /abs/path, ./rel, ~/dir → local repository, resolved to an absolute path
+//   - org/repo                → expanded using the default host and protocol
+//   - group/sub/repo          → nested namespaces (not on github.com, which has none)
+//   - <alias>:<path>          → expanded using the host configured for the alias
+//   - <user>@<host>:<path>    → used as-is (any SSH host)
+//   - <scheme>://<host>/...   → used as-is (https, http, ssh with optional port, file)
 //
 // The repo name is always derived from the last path component (without .git suffix).
-func parseRepoSpec(spec string) (url, repoName string, err error) {
-	// HTTPS or HTTP URL
+func parseRepoSpec(spec string, opts repoSpecOptions) (url, repoName string, err error) {
+	// Local path — checked first so Windows drive letters aren't mistaken for SSH hosts
+	if isLocalPath(spec) {
+		path, err := expandLocalPath(spec)
+		if err != nil {
+			return "", "", err
+		}
+		name := repoNameFromPath(filepath.ToSlash(path), "/")
+		if name == "" {
+			return "", "", fmt.Errorf("cannot determine repository name from path %q", spec)
+		}
+		return path, name, nil
+	}
+
+	// Full URL: https://, http://, ssh://, git://
 	if strings.Contains(spec, "://") {
-		name := repoNameFromPath(strings.TrimSuffix(spec, ".git"), "/")
+		u, err := neturl.Parse(spec)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid URL %q: %w", spec, err)
+		}
+		name := repoNameFromPath(u.Path, "/")
 		if name == "" {
 			return "", "", fmt.Errorf("cannot determine repository name from URL %q", spec)
 		}
 		return spec, name, nil
 	}
 
-	// Generic SSH URL: git@<host>:<path>[.git]
-	if strings.HasPrefix(spec, "git@") {
-		colonIdx := strings.Index(spec, ":")
-		if colonIdx == -1 {
-			return "", "", fmt.Errorf("invalid SSH URL %q: missing ':'", spec)
-		}
-		name := repoNameFromPath(strings.TrimSuffix(spec[colonIdx+1:], ".git"), "/")
+	// SCP-like SSH URL (git@<host>:<path>) or configured alias (<alias>:<path>)
+	if prefix, path, found := strings.Cut(spec, ":"); found && !strings.Contains(prefix, "/") {
+		name := repoNameFromPath(path, "/")
 		if name == "" {
-			return "", "", fmt.Errorf("cannot determine repository name from SSH URL %q", spec)
+			return "", "", fmt.Errorf("cannot determine repository name from %q", spec)
 		}
-		return spec, name, nil
+		if host, ok := opts.Hosts[prefix]; ok {
+			url, err := buildRepoURL(host, path, opts.Protocol)
+			if err != nil {
+				return "", "", err
+			}
+			return url, name, nil
+		}
+		if strings.Contains(prefix, "@") {
+			return spec, name, nil
+		}
+		return "", "", fmt.Errorf("unknown host alias %q in %q\nDefine it with: gwtm config set host.%s <host>", prefix, spec, prefix)
 	}
 
-	// org/repo shorthand — expand to GitHub SSH
-	orgRepoRegex := regexp.MustCompile(`^([a-zA-Z0-9_.-]+)/([a-zA-Z0-9_.-]+)$`)
-	if matches := orgRepoRegex.FindStringSubmatch(spec); matches != nil {
-		org, repo := matches[1], matches[2]
-		return fmt.Sprintf("git@github.com:%s/%s.git", org, repo), repo, nil
+	if strings.HasPrefix(spec, "git@") {
+		return "", "", fmt.Errorf("invalid SSH URL %q: missing ':'", spec)
+	}
+
+	// Shorthand — expand using the default host
+	if shorthandRegex.MatchString(spec) {
+		nested := strings.Count(spec, "/") > 1
+		if nested && opts.DefaultHost == "github.com" {
+			return "", "", fmt.Errorf("invalid repository format %q: GitHub repositories are addressed as org/repo", spec)
+		}
+		url, err := buildRepoURL(opts.DefaultHost, spec, opts.Protocol)
+		if err != nil {
+			return "", "", err
+		}
+		return url, repoNameFromPath(spec, "/"), nil
 	}
 
 	return "", "", fmt.Errorf("invalid repository format %q\nExamples: org/repo, git@github.com:org/repo.git, https://github.com/org/repo", spec)
 }
 
-// repoNameFromPath returns the last non-empty segment of a slash-separated path.
+// isLocalPath reports whether spec is written as a filesystem path rather
+// than a URL or shorthand: absolute, or starting with ./, ../ or ~/
+func isLocalPath(spec string) bool {
+	if filepath.IsAbs(spec) || spec == "." || spec == ".." || spec == "~" {
+		return true
+	}
+	for _, prefix := range []string{"./", "../", "~/", ".\\", "..\\", "~\\"} {
+		if strings.HasPrefix(spec, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandLocalPath resolves ~ and relative paths to a clean absolute path
+func expandLocalPath(spec string) (string, error) {
+	if spec == "~" || strings.HasPrefix(spec, "~/") || strings.HasPrefix(spec, "~\\") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot expand %q: %w", spec, err)
+		}
+		spec = filepath.Join(home, spec[1:])
+	}
+
+	path, err := filepath.Abs(spec)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve path %q: %w", spec, err)
+	}
+	return path, nil
+}
+
+// referenceCachePath returns where the shared object cache for url lives:
+// <cache dir>/objects/<host>/<path>.git
+func referenceCachePath(url string) string {
+	key := url
+	if _, rest, found := strings.Cut(key, "://"); found {
+		key = rest
+	}
+	// Drop the user from user@host forms
+	if at := strings.Index(key, "@"); at >= 0 && !strings.Contains(key[:at], "/") {
+		key = key[at+1:]
+	}
+	key = strings.ReplaceAll(key, ":", "/")
+
+	var parts []string
+	for _, part := range strings.Split(key, "/") {
+		if part != "" && part != "." && part != ".." {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) > 0 {
+		parts[len(parts)-1] = strings.TrimSuffix(parts[len(parts)-1], ".git") + ".git"
+	}
+
+	return filepath.Join(append([]string{config.GetCacheDir(), "objects"}, parts...)...)
+}
+
+// shorthandRegex matches org/repo and nested group/sub/repo shorthand.
+var shorthandRegex = regexp.MustCompile(`^[a-zA-Z0-9_.-]+(/[a-zA-Z0-9_.-]+)+$`)
+
+// buildRepoURL joins a host (or URL prefix) and a repository path into a clone URL.
+// A host containing "://" or "@" is treated as a URL prefix and used verbatim;
+// a bare host name is expanded using protocol.
+func buildRepoURL(host, path, protocol string) (string, error) {
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git") + ".git"
+
+	switch {
+	case strings.Contains(host, "://"):
+		return strings.TrimSuffix(host, "/") + "/" + path, nil
+	case strings.Contains(host, "@"):
+		return strings.TrimSuffix(host, ":") + ":" + path, nil
+	}
+
+	switch protocol {
+	case "ssh":
+		return fmt.Sprintf("git@%s:%s", host, path), nil
+	case "https":
+		return fmt.Sprintf("https://%s/%s", host, path), nil
+	default:
+		return "", fmt.Errorf("unsupported protocol %q: expected ssh or https", protocol)
+	}
+}
+
+// repoNameFromPath returns the last non-empty segment of a slash-separated path,
+// without any .git suffix.
 func repoNameFromPath(path, sep string) string {
 	parts := strings.Split(path, sep)
 	for i := len(parts) - 1; i >= 0; i-- {
 		if parts[i] != "" {
-			return parts[i]
+			return strings.TrimSuffix(parts[i], ".git")
 		}
 	}
 	return ""
@@ -1,9 +1,29 @@
 package commands
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/hooks"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
 )
 
+// defaultRepoSpecOptions returns the expansion rules when nothing is
+// configured: GitHub over SSH
+func defaultRepoSpecOptions() repoSpecOptions {
+	defaults := map[string]string{}
+	for _, k := range config.Keys {
+		defaults[k.Name] = k.Default
+	}
+	return repoSpecOptions{DefaultHost: defaults["defaultHost"], Protocol: defaults["protocol"]}
+}
+
 func TestParseRepoSpec(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -68,7 +88,7 @@ func TestParseRepoSpec(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotURL, gotRepoName, err := parseRepoSpec(tt.spec)
+			gotURL, gotRepoName, err := parseRepoSpec(tt.spec, defaultRepoSpecOptions())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parseRepoSpec(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
 				return
@@ -85,3 +105,337 @@ func TestParseRepoSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRepoSpecWithOptions(t *testing.T) {
+	hosts := map[string]string{
+		"gl":   "gitlab.example.com",
+		"work": "ssh://git@git.work.example:2222",
+		"old":  "git@legacy.example.com:",
+	}
+
+	tests := []struct {
+		name         string
+		spec         string
+		opts         repoSpecOptions
+		wantURL      string
+		wantRepoName string
+		wantErr      bool
+	}{
+		{
+			name:         "shorthand with custom default host",
+			spec:         "acme/webapp",
+			opts:         repoSpecOptions{DefaultHost: "gitlab.example.com", Protocol: "ssh"},
+			wantURL:      "git@gitlab.example.com:acme/webapp.git",
+			wantRepoName: "webapp",
+		},
+		{
+			name:         "shorthand over HTTPS",
+			spec:         "acme/webapp",
+			opts:         repoSpecOptions{DefaultHost: "github.com", Protocol: "https"},
+			wantURL:      "https://github.com/acme/webapp.git",
+			wantRepoName: "webapp",
+		},
+		{
+			name:         "nested subgroups on non-GitHub default host",
+			spec:         "group/sub/repo",
+			opts:         repoSpecOptions{DefaultHost: "gitlab.example.com", Protocol: "https"},
+			wantURL:      "https://gitlab.example.com/group/sub/repo.git",
+			wantRepoName: "repo",
+		},
+		{
+			name:    "nested subgroups on GitHub are invalid",
+			spec:    "group/sub/repo",
+			opts:    defaultRepoSpecOptions(),
+			wantErr: true,
+		},
+		{
+			name:         "host alias with nested subgroups",
+			spec:         "gl:group/sub/repo",
+			opts:         repoSpecOptions{DefaultHost: "github.com", Protocol: "ssh", Hosts: hosts},
+			wantURL:      "git@gitlab.example.com:group/sub/repo.git",
+			wantRepoName: "repo",
+		},
+		{
+			name:         "host alias honours protocol",
+			spec:         "gl:group/repo",
+			opts:         repoSpecOptions{DefaultHost: "github.com", Protocol: "https", Hosts: hosts},
+			wantURL:      "https://gitlab.example.com/group/repo.git",
+			wantRepoName: "repo",
+		},
+		{
+			name:         "host alias with URL prefix and port",
+			spec:         "work:team/repo",
+			opts:         repoSpecOptions{DefaultHost: "github.com", Protocol: "https", Hosts: hosts},
+			wantURL:      "ssh://git@git.work.example:2222/team/repo.git",
+			wantRepoName: "repo",
+		},
+		{
+			name:         "host alias with SCP-style prefix",
+			spec:         "old:team/repo.git",
+			opts:         repoSpecOptions{DefaultHost: "github.com", Protocol: "https", Hosts: hosts},
+			wantURL:      "git@legacy.example.com:team/repo.git",
+			wantRepoName: "repo",
+		},
+		{
+			name:    "unknown host alias",
+			spec:    "nope:team/repo",
+			opts:    repoSpecOptions{DefaultHost: "github.com", Protocol: "ssh", Hosts: hosts},
+			wantErr: true,
+		},
+		{
+			name:         "ssh URL with port",
+			spec:         "ssh://git@git.example.com:2222/team/repo.git",
+			opts:         defaultRepoSpecOptions(),
+			wantURL:      "ssh://git@git.example.com:2222/team/repo.git",
+			wantRepoName: "repo",
+		},
+		{
+			name:    "ssh URL without a path",
+			spec:    "ssh://git@git.example.com:2222",
+			opts:    defaultRepoSpecOptions(),
+			wantErr: true,
+		},
+		{
+			name:         "HTTPS URL with nested subgroups and trailing slash",
+			spec:         "https://gitlab.example.com/group/sub/repo/",
+			opts:         defaultRepoSpecOptions(),
+			wantURL:      "https://gitlab.example.com/group/sub/repo/",
+			wantRepoName: "repo",
+		},
+		{
+			name:         "SSH URL with non-git user",
+			spec:         "deploy@git.example.com:group/sub/repo.git",
+			opts:         defaultRepoSpecOptions(),
+			wantURL:      "deploy@git.example.com:group/sub/repo.git",
+			wantRepoName: "repo",
+		},
+		{
+			name:    "unsupported protocol",
+			spec:    "acme/webapp",
+			opts:    repoSpecOptions{DefaultHost: "github.com", Protocol: "ftp"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotURL, gotRepoName, err := parseRepoSpec(tt.spec, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseRepoSpec(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if gotURL != tt.wantURL {
+				t.Errorf("parseRepoSpec(%q) url = %q, want %q", tt.spec, gotURL, tt.wantURL)
+			}
+			if gotRepoName != tt.wantRepoName {
+				t.Errorf("parseRepoSpec(%q) repoName = %q, want %q", tt.spec, gotRepoName, tt.wantRepoName)
+			}
+		})
+	}
+}
+
+func TestParseRepoSpecLocalPaths(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get current directory: %v", err)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory available")
+	}
+
+	tests := []struct {
+		name         string
+		spec         string
+		wantURL      string
+		wantRepoName string
+	}{
+		{
+			name:         "absolute path to bare repo",
+			spec:         "/srv/git/webapp.git",
+			wantURL:      filepath.Clean("/srv/git/webapp.git"),
+			wantRepoName: "webapp",
+		},
+		{
+			name:         "relative path",
+			spec:         "./mirrors/webapp",
+			wantURL:      filepath.Join(cwd, "mirrors", "webapp"),
+			wantRepoName: "webapp",
+		},
+		{
+			name:         "parent-relative path",
+			spec:         "../webapp.git",
+			wantURL:      filepath.Join(filepath.Dir(cwd), "webapp.git"),
+			wantRepoName: "webapp",
+		},
+		{
+			name:         "home-relative path",
+			spec:         "~/src/webapp",
+			wantURL:      filepath.Join(home, "src", "webapp"),
+			wantRepoName: "webapp",
+		},
+		{
+			name:         "file URL",
+			spec:         "file:///srv/git/webapp.git",
+			wantURL:      "file:///srv/git/webapp.git",
+			wantRepoName: "webapp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if runtime.GOOS == "windows" && strings.HasPrefix(tt.spec, "/") {
+				t.Skip("unix absolute path")
+			}
+			gotURL, gotRepoName, err := parseRepoSpec(tt.spec, defaultRepoSpecOptions())
+			if err != nil {
+				t.Fatalf("parseRepoSpec(%q) error = %v", tt.spec, err)
+			}
+			if gotURL != tt.wantURL {
+				t.Errorf("parseRepoSpec(%q) url = %q, want %q", tt.spec, gotURL, tt.wantURL)
+			}
+			if gotRepoName != tt.wantRepoName {
+				t.Errorf("parseRepoSpec(%q) repoName = %q, want %q", tt.spec, gotRepoName, tt.wantRepoName)
+			}
+		})
+	}
+}
+
+func TestReferenceCachePath(t *testing.T) {
+	cacheDir := t.TempDir()
+	os.Setenv("GIT_WORKTREE_MANAGER_HOME", cacheDir)
+	defer os.Unsetenv("GIT_WORKTREE_MANAGER_HOME")
+	objects := filepath.Join(cacheDir, "cache", "objects")
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"git@github.com:acme/webapp.git", filepath.Join(objects, "github.com", "acme", "webapp.git")},
+		{"https://github.com/acme/webapp", filepath.Join(objects, "github.com", "acme", "webapp.git")},
+		{"ssh://git@git.example.com:2222/team/repo.git", filepath.Join(objects, "git.example.com", "2222", "team", "repo.git")},
+		{"https://evil.example.com/../../etc", filepath.Join(objects, "evil.example.com", "etc.git")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := referenceCachePath(tt.url); got != tt.want {
+				t.Errorf("referenceCachePath(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetupProjectFromLocalPathWithCache(t *testing.T) {
+	root, _ := setupTestProject(t)
+	upstream := filepath.Join(filepath.Dir(root), "upstream.git")
+
+	installDir := t.TempDir()
+	os.Setenv("GIT_WORKTREE_MANAGER_HOME", installDir)
+	defer os.Unsetenv("GIT_WORKTREE_MANAGER_HOME")
+
+	// Projects outlive their subtest, as the registry still lists them
+	projectsDir := t.TempDir()
+
+	tests := []struct {
+		name           string
+		url            string
+		wantAlternates bool
+	}{
+		{name: "plain local path skips the cache", url: upstream, wantAlternates: false},
+		{name: "file URL uses the cache", url: "file://" + upstream, wantAlternates: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoDir := filepath.Join(projectsDir, fmt.Sprintf("project%d", i))
+			if _, err := setupProject(tt.url, repoDir, setupOptions{UseCache: true, Settings: loadSettings("")}, func(string, string) {}); err != nil {
+				t.Fatalf("setupProject() error = %v", err)
+			}
+
+			_, err := os.Stat(filepath.Join(repoDir, ".bare", "objects", "info", "alternates"))
+			if gotAlternates := err == nil; gotAlternates != tt.wantAlternates {
+				t.Errorf("alternates present = %v, want %v", gotAlternates, tt.wantAlternates)
+			}
+			if failing := failingChecks(repoDir); len(failing) > 0 {
+				t.Errorf("doctorChecks() reported problems: %v", failing)
+			}
+		})
+	}
+}
+
+func TestSetupProject_HookFailureIsReturned(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are shell scripts here")
+	}
+
+	root, _ := setupTestProject(t)
+	upstream := filepath.Join(filepath.Dir(root), "upstream.git")
+	os.MkdirAll(config.GetHooksDir(), 0755)
+	os.WriteFile(filepath.Join(config.GetHooksDir(), hooks.PostSetup), []byte("#!/bin/sh\nexit 3\n"), 0755)
+
+	// Bootstrap runs setups in parallel and prints their errors itself, so
+	// setupProject must only return them
+	errorsBefore := ui.ErrorCount()
+	repoDir := filepath.Join(filepath.Dir(root), "hooked")
+	branch, err := setupProject(upstream, repoDir, setupOptions{Settings: loadSettings("")}, func(string, string) {})
+	if err == nil {
+		t.Fatal("setupProject() with a failing hook error = nil, want error")
+	}
+	if branch == "" {
+		t.Error("setupProject() with a failing hook returned no branch, want the project kept")
+	}
+	if _, statErr := os.Stat(filepath.Join(repoDir, worktreeDirName(branch))); statErr != nil {
+		t.Errorf("setupProject() with a failing hook removed the worktree: %v", statErr)
+	}
+	if ui.ErrorCount() != errorsBefore {
+		t.Error("setupProject() printed an error instead of only returning it")
+	}
+}
+
+func TestSetupProjectWithProfile(t *testing.T) {
+	root, _ := setupTestProject(t)
+	upstream := filepath.Join(filepath.Dir(root), "upstream.git")
+	t.Setenv("GWTM_PROFILE", "")
+
+	userFile := config.GetConfigPath()
+	os.MkdirAll(filepath.Dir(userFile), 0755)
+	os.WriteFile(userFile, []byte("[profiles.norebase]\nbranch.autosetuprebase = \"never\"\n"), 0644)
+
+	repoDir := filepath.Join(filepath.Dir(root), "norebase")
+	if _, err := setupProject(upstream, repoDir, setupOptions{Profile: "norebase", Settings: loadSettings("")}, func(string, string) {}); err != nil {
+		t.Fatalf("setupProject() error = %v", err)
+	}
+
+	bare := git.NewClient(filepath.Join(repoDir, ".bare"))
+	for key, want := range map[string]string{"branch.autosetuprebase": "never", "push.default": "current", "gwtm.profile": "norebase"} {
+		if got, _ := bare.GetConfig(key); got != want {
+			t.Errorf("after setupProject() %s = %q, want %q", key, got, want)
+		}
+	}
+	if failing := failingChecks(repoDir); len(failing) > 0 {
+		t.Errorf("doctorChecks() reported problems: %v", failing)
+	}
+
+	missing := filepath.Join(filepath.Dir(root), "missing")
+	if _, err := setupProject(upstream, missing, setupOptions{Profile: "missing", Settings: loadSettings("")}, func(string, string) {}); err == nil {
+		t.Error("setupProject() with an unknown profile error = nil, want error")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Error("setupProject() with an unknown profile left the project directory behind")
+	}
+
+	// The caller's settings are used as they are, not read again from the files
+	settings := loadSettings("")
+	settings.SetFlag("profiles.flagged.push.default", "--test", "upstream")
+	flagged := filepath.Join(filepath.Dir(root), "flagged")
+	if _, err := setupProject(upstream, flagged, setupOptions{Profile: "flagged", Settings: settings}, func(string, string) {}); err != nil {
+		t.Fatalf("setupProject() with a profile from the caller's settings error = %v", err)
+	}
+	if got, _ := git.NewClient(filepath.Join(flagged, ".bare")).GetConfig("push.default"); got != "upstream" {
+		t.Errorf("after setupProject() push.default = %q, want upstream from the caller's settings", got)
+	}
+}
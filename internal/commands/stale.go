@@ -0,0 +1,252 @@
+package commands
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var staleCmd = &cobra.Command{
+	Use:   "stale",
+	Short: "Find worktrees that have not been worked on for a while",
+	Long: `List worktrees with no commits and no file changes for longer than --older-than,
+and offer to remove each one through the same checks as 'gwtm remove'.
+
+Worktrees whose upstream is gone (usually merged and deleted) are listed first,
+then those without an upstream, then the rest; within each group the longest
+inactive come first. The default branch's worktree is never listed.`,
+	Args: cobra.NoArgs,
+	Run:  runStale,
+}
+
+func init() {
+	staleCmd.Flags().String("older-than", "30d", "Inactivity threshold, e.g. 30d, 2w or 36h")
+	staleCmd.Flags().Bool("remove", false, "Remove every stale worktree without asking")
+	staleCmd.Flags().Bool("no-remove", false, "Only list stale worktrees")
+	rootCmd.AddCommand(staleCmd)
+}
+
+// worktreeActivity records when a worktree was last worked on
+type worktreeActivity struct {
+	Name         string // Worktree directory name
+	Worktree     git.Worktree
+	LastCommit   time.Time
+	LastModified time.Time // Newest tracked or untracked file; zero when there are none
+	Upstream     git.Upstream
+}
+
+// LastActive returns the later of the last commit and the last file change
+func (a worktreeActivity) LastActive() time.Time {
+	if a.LastModified.After(a.LastCommit) {
+		return a.LastModified
+	}
+	return a.LastCommit
+}
+
+func runStale(cmd *cobra.Command, args []string) {
+	olderThanFlag, _ := cmd.Flags().GetString("older-than")
+	olderThan, err := parseAge(olderThanFlag)
+	if err != nil {
+		ui.PrintError(err, "Use a number of days, weeks or hours, e.g. --older-than 30d")
+		return
+	}
+	remove, err := boolChoice(cmd, "remove")
+	if err != nil {
+		ui.PrintError(err, "Pass only one of them")
+		return
+	}
+
+	root, err := findWorktreeRoot()
+	if err != nil {
+		ui.PrintError(err, "Run this command from within a worktree-managed repository")
+		return
+	}
+
+	stale, err := staleWorktrees(root, olderThan, time.Now())
+	if err != nil {
+		ui.PrintError(err, "Failed to inspect worktrees")
+		return
+	}
+	if len(stale) == 0 {
+		ui.PrintStatus("✅", "No worktrees inactive for more than "+olderThanFlag)
+		return
+	}
+
+	printStaleWorktrees(stale, olderThanFlag, time.Now())
+
+	if remove == nil && !assumeYes && !interactive() {
+		ui.PrintStatus("💡", "Rerun with --remove to remove them, or remove them one at a time with 'gwtm remove'")
+		return
+	}
+
+	removed := 0
+	for _, activity := range stale {
+		answer, err := confirm("🗑", "Remove '"+activity.Name+"'?", remove)
+		if err != nil {
+			ui.PrintError(err, confirmGuidance("--remove or --no-remove"))
+			return
+		}
+		if answer && removeWorktree(root, activity.Name, false) {
+			removed++
+		}
+	}
+
+	if !GetDryRun() {
+		ui.PrintStatus("📊", fmt.Sprintf("Removed %d of %d stale worktree(s)", removed, len(stale)))
+	}
+}
+
+// parseAge parses an inactivity threshold: a whole number of days (30d) or
+// weeks (2w), or anything time.ParseDuration accepts (36h)
+func parseAge(s string) (time.Duration, error) {
+	var age time.Duration
+	var err error
+	if n, found := strings.CutSuffix(s, "d"); found {
+		var days int
+		days, err = strconv.Atoi(n)
+		age = time.Duration(days) * 24 * time.Hour
+	} else if n, found := strings.CutSuffix(s, "w"); found {
+		var weeks int
+		weeks, err = strconv.Atoi(n)
+		age = time.Duration(weeks) * 7 * 24 * time.Hour
+	} else {
+		age, err = time.ParseDuration(s)
+	}
+	if err != nil || age <= 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return age, nil
+}
+
+// staleWorktrees returns the worktrees in root that have been inactive for
+// longer than olderThan at now, most likely to be abandoned first
+func staleWorktrees(root string, olderThan time.Duration, now time.Time) ([]worktreeActivity, error) {
+	client := git.NewClient(root)
+	worktrees, err := client.WorktreeListPorcelain()
+	if err != nil {
+		return nil, err
+	}
+	defaultBranch, _ := client.DetectDefaultBranch()
+
+	var stale []worktreeActivity
+	for _, wt := range worktrees {
+		// Only worktrees that 'gwtm remove' can address by name
+		if wt.Bare || wt.Prunable || filepath.Dir(wt.Path) != filepath.Clean(root) {
+			continue
+		}
+		if defaultBranch != "" && wt.Branch == defaultBranch {
+			continue
+		}
+
+		activity, err := readWorktreeActivity(wt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(wt.Path), err)
+		}
+		if now.Sub(activity.LastActive()) > olderThan {
+			stale = append(stale, activity)
+		}
+	}
+
+	slices.SortStableFunc(stale, func(a, b worktreeActivity) int {
+		return cmp.Or(
+			cmp.Compare(upstreamRank(a), upstreamRank(b)),
+			a.LastActive().Compare(b.LastActive()),
+		)
+	})
+	return stale, nil
+}
+
+// readWorktreeActivity finds when wt last had a commit or a file change
+func readWorktreeActivity(wt git.Worktree) (worktreeActivity, error) {
+	activity := worktreeActivity{Name: filepath.Base(wt.Path), Worktree: wt}
+	client := git.NewClient(wt.Path)
+
+	lastCommit, err := client.LastCommitTime()
+	if err != nil {
+		return activity, err
+	}
+	activity.LastCommit = lastCommit
+
+	files, err := client.ListFiles()
+	if err != nil {
+		return activity, err
+	}
+	for _, file := range files {
+		// Deleted tracked files are still listed; they have no time to offer
+		info, err := os.Lstat(filepath.Join(wt.Path, file))
+		if err == nil && info.ModTime().After(activity.LastModified) {
+			activity.LastModified = info.ModTime()
+		}
+	}
+
+	if !wt.Detached {
+		upstream, err := client.BranchUpstream(wt.Branch)
+		if err != nil {
+			return activity, err
+		}
+		activity.Upstream = upstream
+	}
+	return activity, nil
+}
+
+// upstreamRank orders worktrees by how likely their branch is finished with:
+// upstream gone, then no upstream, then an open upstream
+func upstreamRank(a worktreeActivity) int {
+	switch {
+	case a.Upstream.Gone:
+		return 0
+	case a.Upstream.Name == "":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// printStaleWorktrees lists stale worktrees with what made them stale
+func printStaleWorktrees(stale []worktreeActivity, olderThan string, now time.Time) {
+	width := 0
+	for _, activity := range stale {
+		width = max(width, len(activity.Name))
+	}
+
+	ui.PrintStatus("🕰️", fmt.Sprintf("%d worktree(s) inactive for more than %s:", len(stale), olderThan))
+	for _, activity := range stale {
+		upstream := "tracks " + activity.Upstream.Name
+		switch {
+		case activity.Worktree.Detached:
+			upstream = "detached HEAD"
+		case activity.Upstream.Gone:
+			upstream = "upstream " + activity.Upstream.Name + " gone"
+		case activity.Upstream.Name == "":
+			upstream = "no upstream"
+		}
+
+		lastChange := "no files"
+		if !activity.LastModified.IsZero() {
+			lastChange = "last change " + daysAgo(now, activity.LastModified)
+		}
+
+		fmt.Printf("  %-*s  last commit %s  %s  %s\n", width, activity.Name, daysAgo(now, activity.LastCommit), lastChange, upstream)
+	}
+}
+
+// daysAgo describes how long before now t was, in whole days
+func daysAgo(now, t time.Time) string {
+	switch days := int(now.Sub(t).Hours() / 24); days {
+	case 0:
+		return "today"
+	case 1:
+		return "1 day ago"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "30d", want: 30 * 24 * time.Hour},
+		{input: "2w", want: 14 * 24 * time.Hour},
+		{input: "36h", want: 36 * time.Hour},
+		{input: "0d", wantErr: true},
+		{input: "-3d", wantErr: true},
+		{input: "xd", wantErr: true},
+		{input: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseAge(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAge(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseAge(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaleWorktrees(t *testing.T) {
+	root, branch := setupTestProject(t)
+	client := git.NewClient(root)
+
+	// ageWorktree backdates every file in path to when
+	ageWorktree := func(path string, when time.Time) {
+		t.Helper()
+		files, err := git.NewClient(path).ListFiles()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range files {
+			os.Chtimes(filepath.Join(path, file), when, when)
+		}
+	}
+
+	// Commits made from here on are dated a year ago
+	old := time.Now().AddDate(-1, 0, 0)
+	t.Setenv("GIT_COMMITTER_DATE", old.Format(time.RFC3339))
+
+	abandoned := addTestWorktree(t, root, "abandoned", branch)
+	commitFile(t, abandoned, "work.txt", "work\n")
+	ageWorktree(abandoned, old)
+	client.ExecGit("branch", "--unset-upstream", "abandoned")
+
+	merged := addTestWorktree(t, root, "merged", branch)
+	commitFile(t, merged, "done.txt", "done\n")
+	ageWorktree(merged, old.AddDate(0, 1, 0))
+	client.ExecGit("config", "branch.merged.remote", "origin")
+	client.ExecGit("config", "branch.merged.merge", "refs/heads/merged") // never pushed, so gone
+
+	// Old commits, but a file was touched today
+	touched := addTestWorktree(t, root, "touched", branch)
+	commitFile(t, touched, "notes.txt", "notes\n")
+	ageWorktree(touched, old)
+	os.WriteFile(filepath.Join(touched, "notes.txt"), []byte("more notes\n"), 0644)
+
+	stale, err := staleWorktrees(root, 30*24*time.Hour, time.Now())
+	if err != nil {
+		t.Fatalf("staleWorktrees() error = %v", err)
+	}
+
+	var names []string
+	for _, activity := range stale {
+		names = append(names, activity.Name)
+	}
+	// Gone upstream ranks first, and recent file changes keep a worktree off the list
+	want := []string{"merged", "abandoned"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("staleWorktrees() = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+)
+
+// initSubmodules initialises every submodule of the worktree at worktreePath,
+// reporting progress and failures per submodule through status. When
+// shareFrom is another worktree of the same project, objects of submodules
+// already checked out there are copied instead of being downloaded again.
+// Every submodule is tried; the returned error describes those that failed.
+func initSubmodules(worktreePath, shareFrom string, status func(emoji, message string)) error {
+	client := git.NewClient(worktreePath)
+
+	paths, err := client.SubmodulePaths()
+	if err != nil {
+		return withGuidance(err, "Initialise submodules manually with 'git submodule update --init --recursive'")
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	status("🧩", fmt.Sprintf("Initialising %d submodule(s)", len(paths)))
+
+	var failed []error
+	for i, path := range paths {
+		reference := ""
+		if shareFrom != "" {
+			candidate := filepath.Join(shareFrom, filepath.FromSlash(path))
+			if _, err := os.Stat(filepath.Join(candidate, ".git")); err == nil {
+				reference = candidate
+			}
+		}
+
+		status("📥", fmt.Sprintf("[%d/%d] %s", i+1, len(paths), path))
+		if err := client.SubmoduleUpdate(path, reference); err != nil {
+			failed = append(failed, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return withGuidance(fmt.Errorf("%d of %d submodule(s) failed to initialise: %w", len(failed), len(paths), errors.Join(failed...)),
+			"Retry with 'git submodule update --init --recursive' inside "+worktreePath)
+	}
+	return nil
+}
+
+// submoduleShareSource returns an existing worktree of the project other than
+// exclude, whose submodule checkouts can supply objects to a new worktree.
+// Returns "" when there is none.
+func submoduleShareSource(root, exclude string) string {
+	worktrees, err := git.NewClient(root).WorktreeListPorcelain()
+	if err != nil {
+		return ""
+	}
+	for _, wt := range worktrees {
+		if !wt.Bare && !wt.Prunable && !samePath(wt.Path, exclude) {
+			return wt.Path
+		}
+	}
+	return ""
+}
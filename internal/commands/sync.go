@@ -0,0 +1,253 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/config"
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Update every worktree from its upstream",
+	Long: `Fetch from origin once, then bring every clean worktree up to date with its upstream branch.
+
+Worktrees are fast-forwarded; with --rebase (or gwtm.syncMode=rebase) their own
+commits are rebased onto the upstream instead. Worktrees with local changes,
+without an upstream or with a detached HEAD are never touched, and a rebase
+that hits a conflict is aborted so the worktree is left as it was.`,
+	Args: cobra.NoArgs,
+	Run:  runSync,
+}
+
+func init() {
+	syncCmd.Flags().Bool("rebase", false, "Rebase local commits onto the upstream instead of only fast-forwarding (default from gwtm.syncMode)")
+	syncCmd.Flags().Bool("no-fetch", false, "Update from the remote-tracking branches as they are, without fetching")
+	syncCmd.Flags().Int("jobs", 4, "Maximum number of worktrees updated in parallel")
+	rootCmd.AddCommand(syncCmd)
+}
+
+// syncOutcome is what happened to a worktree during sync
+type syncOutcome int
+
+const (
+	syncUpdated  syncOutcome = iota // Moved to (or onto) its upstream
+	syncUpToDate                    // Already had everything from its upstream
+	syncSkipped                     // Left alone; Detail says why
+	syncConflict                    // The rebase stopped on a conflict and was aborted
+	syncFailed                      // Something went wrong; Err says what
+)
+
+type syncResult struct {
+	Worktree git.Worktree
+	Outcome  syncOutcome
+	Detail   string
+	Err      error
+}
+
+func runSync(cmd *cobra.Command, args []string) {
+	root, err := findWorktreeRoot()
+	if err != nil {
+		ui.PrintError(err, "Run this command from within a worktree-managed repository")
+		return
+	}
+
+	settings := loadSettings(root)
+	if cmd.Flags().Changed("rebase") {
+		mode := "ff"
+		if rebase, _ := cmd.Flags().GetBool("rebase"); rebase {
+			mode = "rebase"
+		}
+		settings.SetFlag("syncMode", "--rebase", mode)
+	}
+	rebase, err := syncRebase(settings)
+	if err != nil {
+		ui.PrintError(err, "Set gwtm.syncMode to ff or rebase")
+		return
+	}
+	noFetch, _ := cmd.Flags().GetBool("no-fetch")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+
+	client := git.NewClient(root)
+	client.DryRun = GetDryRun()
+
+	mode := "fast-forward"
+	if rebase {
+		mode = "rebase"
+	}
+
+	if client.DryRun {
+		if !noFetch {
+			ui.PrintDryRun("Would fetch from origin")
+		}
+		ui.PrintDryRun("Would " + mode + " every clean worktree with an upstream")
+		return
+	}
+
+	all, err := client.WorktreeListPorcelain()
+	if err != nil {
+		ui.PrintError(err, "Failed to list worktrees")
+		return
+	}
+	var worktrees []git.Worktree
+	for _, wt := range all {
+		if !wt.Bare && !wt.Prunable {
+			worktrees = append(worktrees, wt)
+		}
+	}
+	if len(worktrees) == 0 {
+		ui.PrintStatus("ℹ️", "No worktrees to sync")
+		return
+	}
+
+	if noFetch {
+		ui.PrintStatus("⏭️", "Skipping fetch — using local refs")
+	} else {
+		ui.PrintStatus("📡", "Fetching from origin")
+		err := client.Fetch(true, false)
+		switch {
+		case git.IsNetworkError(err):
+			ui.PrintStatus("⚠️", "origin is unreachable — syncing with local refs")
+		case err != nil:
+			ui.PrintError(err, "Check the remote, or use --no-fetch to sync with local refs")
+			return
+		}
+	}
+
+	jobs = min(max(jobs, 1), len(worktrees))
+	results := make([]syncResult, len(worktrees))
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+
+	ui.PrintStatus("🔄", fmt.Sprintf("Syncing %d worktrees (%s, %d at a time)", len(worktrees), mode, jobs))
+
+	for i, wt := range worktrees {
+		wg.Add(1)
+		go func(i int, wt git.Worktree) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = syncWorktree(wt, rebase)
+		}(i, wt)
+	}
+	wg.Wait()
+
+	printSyncResults(root, results)
+}
+
+// syncRebase reports whether the syncMode setting asks for rebasing
+func syncRebase(settings *config.Settings) (bool, error) {
+	switch mode := settings.Get("syncMode"); mode {
+	case "ff":
+		return false, nil
+	case "rebase":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown gwtm.syncMode %q", mode)
+	}
+}
+
+// syncWorktree brings one worktree up to date with its upstream, as of the
+// last fetch. Worktrees with local changes are never touched.
+func syncWorktree(wt git.Worktree, rebase bool) syncResult {
+	result := syncResult{Worktree: wt, Outcome: syncSkipped}
+	if wt.Detached {
+		result.Detail = "detached HEAD"
+		return result
+	}
+
+	client := git.NewClient(wt.Path)
+	clean, err := client.IsClean()
+	if err != nil {
+		return syncResult{Worktree: wt, Outcome: syncFailed, Err: err}
+	}
+	if !clean {
+		result.Detail = "local changes"
+		return result
+	}
+
+	upstream, err := client.BranchUpstream(wt.Branch)
+	switch {
+	case err != nil:
+		return syncResult{Worktree: wt, Outcome: syncFailed, Err: err}
+	case upstream.Name == "":
+		result.Detail = "no upstream"
+		return result
+	case upstream.Gone:
+		result.Detail = "upstream " + upstream.Name + " is gone"
+		return result
+	case upstream.Behind == 0:
+		return syncResult{Worktree: wt, Outcome: syncUpToDate}
+	case upstream.Ahead > 0 && !rebase:
+		result.Detail = "diverged from " + upstream.Name + " — use --rebase"
+		return result
+	}
+
+	if upstream.Ahead == 0 {
+		if err := client.MergeFastForward(upstream.Name); err != nil {
+			return syncResult{Worktree: wt, Outcome: syncFailed, Err: err}
+		}
+		result.Outcome = syncUpdated
+		result.Detail = fmt.Sprintf("fast-forwarded %d commit(s) from %s", upstream.Behind, upstream.Name)
+		return result
+	}
+
+	if err := client.Rebase(upstream.Name); err != nil {
+		if abortErr := client.RebaseAbort(); abortErr != nil {
+			return syncResult{Worktree: wt, Outcome: syncFailed, Err: fmt.Errorf("rebase onto %s stopped and could not be aborted — finish or abort it in %s: %w", upstream.Name, wt.Path, abortErr)}
+		}
+		result.Outcome = syncConflict
+		result.Detail = "conflict with " + upstream.Name + " — rebase aborted, worktree unchanged"
+		return result
+	}
+	result.Outcome = syncUpdated
+	result.Detail = fmt.Sprintf("rebased %d local commit(s) onto %s", upstream.Ahead, upstream.Name)
+	return result
+}
+
+// printSyncResults reports each worktree's outcome and a summary, and fails
+// the command if any worktree could not be updated
+func printSyncResults(root string, results []syncResult) {
+	names := make([]string, len(results))
+	width := 0
+	for i, result := range results {
+		names[i] = worktreeName(root, result.Worktree.Path)
+		width = max(width, len(names[i]))
+	}
+
+	counts := make(map[syncOutcome]int)
+	for i, result := range results {
+		counts[result.Outcome]++
+
+		emoji, detail := "⏭️", result.Detail
+		switch result.Outcome {
+		case syncUpdated:
+			emoji = "⬆️"
+		case syncUpToDate:
+			emoji, detail = "✅", "already up to date"
+		case syncSkipped:
+			detail = "skipped: " + detail
+		case syncConflict:
+			emoji = "⚠️"
+		case syncFailed:
+			emoji, detail = "❌", "failed"
+		}
+		ui.PrintStatus(emoji, fmt.Sprintf("%-*s  %s", width, names[i], detail))
+	}
+
+	ui.PrintStatus("📊", fmt.Sprintf("Sync summary: %d updated, %d up to date, %d skipped, %d conflicts, %d failed",
+		counts[syncUpdated], counts[syncUpToDate], counts[syncSkipped], counts[syncConflict], counts[syncFailed]))
+
+	if n := counts[syncConflict]; n > 0 {
+		ui.PrintError(fmt.Errorf("%d worktree(s) hit a conflict while rebasing", n), "Rebase them by hand — they were left as they were")
+	}
+	for i, result := range results {
+		if result.Outcome == syncFailed {
+			ui.PrintError(fmt.Errorf("%s: %w", names[i], result.Err), "Fix the problem and rerun 'gwtm sync'")
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+)
+
+// commitFile writes content to file in dir and commits it
+func commitFile(t *testing.T, dir, file, content string) {
+	t.Helper()
+	client := git.NewClient(dir)
+	os.WriteFile(filepath.Join(dir, file), []byte(content), 0644)
+	client.ExecGit("add", file)
+	if _, _, err := client.ExecGit("commit", "-m", "Change "+file); err != nil {
+		t.Fatalf("failed to commit %s: %v", file, err)
+	}
+}
+
+func TestSyncWorktree(t *testing.T) {
+	root, branch := setupTestProject(t)
+	client := git.NewClient(root)
+
+	// tracking adds a worktree for a new branch tracking origin/<branch>
+	tracking := func(name string) git.Worktree {
+		t.Helper()
+		if _, _, err := client.ExecGit("branch", "--track", name, "origin/"+branch); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(root, name)
+		if err := client.WorktreeAdd(path, name, false); err != nil {
+			t.Fatal(err)
+		}
+		return git.Worktree{Path: path, Branch: name}
+	}
+
+	behind := tracking("behind")
+	dirty := tracking("dirty")
+	os.WriteFile(filepath.Join(dirty.Path, "scratch.txt"), []byte("wip\n"), 0644)
+	ahead := tracking("ahead")
+	commitFile(t, ahead.Path, "ahead.txt", "ahead\n")
+	conflicting := tracking("conflicting")
+	commitFile(t, conflicting.Path, "README.md", "# Mine\n")
+	noUpstream := git.Worktree{Path: addTestWorktree(t, root, "no-upstream", branch), Branch: "no-upstream"}
+	client.ExecGit("branch", "--unset-upstream", "no-upstream")
+
+	// Someone else changes README.md on origin
+	srcDir := filepath.Join(filepath.Dir(root), "src")
+	commitFile(t, srcDir, "README.md", "# Theirs\n")
+	if _, _, err := git.NewClient(srcDir).ExecGit("push", filepath.Join(filepath.Dir(root), "upstream.git"), "HEAD:"+branch); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Fetch(true, false); err != nil {
+		t.Fatal(err)
+	}
+
+	head := func(wt git.Worktree) string {
+		out, _, _ := git.NewClient(wt.Path).ExecGit("rev-parse", "HEAD")
+		return strings.TrimSpace(out)
+	}
+	dirtyHead, conflictingHead := head(dirty), head(conflicting)
+
+	tests := []struct {
+		name   string
+		wt     git.Worktree
+		rebase bool
+		want   syncOutcome
+	}{
+		{"behind is fast-forwarded", behind, false, syncUpdated},
+		{"behind is up to date on rerun", behind, false, syncUpToDate},
+		{"dirty is skipped", dirty, true, syncSkipped},
+		{"no upstream is skipped", noUpstream, true, syncSkipped},
+		{"detached is skipped", git.Worktree{Path: behind.Path, Detached: true}, true, syncSkipped},
+		{"diverged is skipped without rebase", ahead, false, syncSkipped},
+		{"diverged is rebased", ahead, true, syncUpdated},
+		{"conflict is aborted", conflicting, true, syncConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := syncWorktree(tt.wt, tt.rebase)
+			if got.Outcome != tt.want {
+				t.Errorf("syncWorktree() outcome = %d (%s, %v), want %d", got.Outcome, got.Detail, got.Err, tt.want)
+			}
+		})
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(behind.Path, "README.md")); string(content) != "# Theirs\n" {
+		t.Errorf("behind README.md = %q, want the upstream's content", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(ahead.Path, "ahead.txt")); string(content) != "ahead\n" {
+		t.Error("rebase lost the worktree's own commit")
+	}
+	if head(dirty) != dirtyHead {
+		t.Error("syncWorktree() moved a worktree with local changes")
+	}
+	if head(conflicting) != conflictingHead {
+		t.Error("syncWorktree() left a conflicting worktree moved")
+	}
+	if clean, _ := git.NewClient(conflicting.Path).IsClean(); !clean {
+		t.Error("syncWorktree() left a conflicting worktree mid-rebase")
+	}
+}
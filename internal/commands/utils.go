@@ -1,9 +1,12 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/ui"
 )
 
 // findWorktreeRoot walks up from the current directory to find the root of a
@@ -31,3 +34,30 @@ func findWorktreeRoot() (string, error) {
 
 	return "", fmt.Errorf("not in a worktree-managed repository")
 }
+
+// guidedError pairs an error with the actionable guidance printed alongside it,
+// so helpers shared between commands can report failures the same way the
+// commands themselves do.
+type guidedError struct {
+	err      error
+	guidance string
+}
+
+func (e *guidedError) Error() string { return e.err.Error() }
+func (e *guidedError) Unwrap() error { return e.err }
+
+// withGuidance attaches guidance to err
+func withGuidance(err error, guidance string) error {
+	return &guidedError{err: err, guidance: guidance}
+}
+
+// printGuidedError prints err with its attached guidance, or with fallback if
+// err carries none.
+func printGuidedError(err error, fallback string) {
+	var ge *guidedError
+	if errors.As(err, &ge) {
+		ui.PrintError(err, ge.guidance)
+		return
+	}
+	ui.PrintError(err, fallback)
+}
@@ -0,0 +1,322 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Manifests use the subset of YAML that a list of repositories needs: block
+// mappings and sequences nested by indentation, plain and quoted scalars, and
+// comments. Flow collections, block scalars, anchors, tags and multiple
+// documents are rejected rather than misread.
+
+// yamlNode is a parsed YAML value: a scalar, a sequence or a mapping. A key
+// with no value holds an empty scalar.
+type yamlNode struct {
+	Line   int // Line the value starts on, for error messages
+	Scalar string
+	Seq    []*yamlNode
+	Map    []yamlPair // In file order
+	Kind   yamlKind
+}
+
+type yamlKind int
+
+const (
+	yamlScalar yamlKind = iota
+	yamlSeq
+	yamlMap
+)
+
+// yamlPair is one key of a mapping
+type yamlPair struct {
+	Key   string
+	Value *yamlNode
+}
+
+// yamlLine is a line with content, its comment removed
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// parseYAML reads a YAML document
+func parseYAML(data string) (*yamlNode, error) {
+	p := &yamlParser{}
+	for i, raw := range strings.Split(data, "\n") {
+		raw = strings.TrimSuffix(raw, "\r")
+		text := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs can't indent YAML", i+1)
+		}
+		text = strings.TrimSpace(stripYAMLComment(text))
+		switch {
+		case text == "":
+			continue
+		case text == "---" && len(p.lines) == 0:
+			continue
+		case text == "---" || text == "...":
+			return nil, fmt.Errorf("line %d: only one document is supported", i+1)
+		case strings.HasPrefix(text, "%"):
+			return nil, fmt.Errorf("line %d: directives are not supported", i+1)
+		}
+		p.lines = append(p.lines, yamlLine{num: i + 1, indent: len(raw) - len(strings.TrimLeft(raw, " ")), text: text})
+	}
+
+	if len(p.lines) == 0 {
+		return &yamlNode{Kind: yamlMap}, nil
+	}
+	node, err := p.parseBlock(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].num)
+	}
+	return node, nil
+}
+
+// stripYAMLComment removes a # comment: one at the start of the text or
+// after a space, outside quotes
+func stripYAMLComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\', quote == '\'' && c == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.ContainsRune(" :-[{,", rune(text[i-1]))):
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' '):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// isYAMLItem reports whether text starts a sequence item
+func isYAMLItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// parseBlock parses the sequence or mapping whose lines start at indent
+func (p *yamlParser) parseBlock(indent int) (*yamlNode, error) {
+	line := p.lines[p.pos]
+	if isYAMLItem(line.text) {
+		return p.parseSeq(indent)
+	}
+	if _, _, ok := splitYAMLKey(line.text); ok {
+		return p.parseMap(indent)
+	}
+	return nil, fmt.Errorf("line %d: expected a list item or key: value", line.num)
+}
+
+func (p *yamlParser) parseSeq(indent int) (*yamlNode, error) {
+	node := &yamlNode{Line: p.lines[p.pos].num, Kind: yamlSeq}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		rest := strings.TrimPrefix(line.text[1:], " ")
+
+		var item *yamlNode
+		var err error
+		switch {
+		case rest == "":
+			// The item's value is on the following, more indented lines
+			p.pos++
+			item, err = p.parseValue(line, indent, "")
+		case isYAMLItem(rest):
+			return nil, fmt.Errorf("line %d: write nested list items on their own lines", line.num)
+		default:
+			if _, _, ok := splitYAMLKey(rest); ok {
+				// "- key: value" starts a mapping indented to the key
+				p.lines[p.pos] = yamlLine{num: line.num, indent: line.indent + len(line.text) - len(rest), text: rest}
+				item, err = p.parseMap(p.lines[p.pos].indent)
+			} else {
+				p.pos++
+				item, err = parseYAMLScalar(rest, line.num)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		node.Seq = append(node.Seq, item)
+	}
+	return node, nil
+}
+
+func (p *yamlParser) parseMap(indent int) (*yamlNode, error) {
+	node := &yamlNode{Line: p.lines[p.pos].num, Kind: yamlMap}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && !isYAMLItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		key, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", line.num)
+		}
+		for _, pair := range node.Map {
+			if pair.Key == key {
+				return nil, fmt.Errorf("line %d: %q appears twice", line.num, key)
+			}
+		}
+		p.pos++
+
+		value, err := p.parseValue(line, indent, rest)
+		if err != nil {
+			return nil, err
+		}
+		node.Map = append(node.Map, yamlPair{Key: key, Value: value})
+	}
+	return node, nil
+}
+
+// parseValue parses the value that follows a key or item marker on line:
+// rest when it is on the same line, otherwise the block on the next lines.
+// A mapping's value may be a sequence at the mapping's own indent.
+func (p *yamlParser) parseValue(line yamlLine, indent int, rest string) (*yamlNode, error) {
+	if rest != "" {
+		return parseYAMLScalar(rest, line.num)
+	}
+	if p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		if next.indent > indent || (next.indent == indent && isYAMLItem(next.text) && !isYAMLItem(line.text)) {
+			return p.parseBlock(next.indent)
+		}
+	}
+	return &yamlNode{Line: line.num}, nil
+}
+
+// splitYAMLKey splits "key: value" or "key:" into the key and the rest
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingYAMLQuote(text)
+		if end < 0 || !strings.HasPrefix(text[end+1:], ":") {
+			return "", "", false
+		}
+		key, err := unquoteYAML(text[:end+1])
+		if err != nil {
+			return "", "", false
+		}
+		rest = text[end+2:]
+		if rest != "" && rest[0] != ' ' {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(rest), true
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i == len(text)-1 || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), i > 0
+		}
+	}
+	return "", "", false
+}
+
+// closingYAMLQuote returns the index of the quote that closes the quoted
+// scalar text starts with, or -1
+func closingYAMLQuote(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case quote == '\'' && text[i] == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// parseYAMLScalar parses a value written on one line
+func parseYAMLScalar(text string, line int) (*yamlNode, error) {
+	fail := func(format string, args ...any) (*yamlNode, error) {
+		return nil, fmt.Errorf("line %d: "+format, append([]any{line}, args...)...)
+	}
+
+	switch text[0] {
+	case '"', '\'':
+		end := closingYAMLQuote(text)
+		if end < 0 {
+			return fail("unterminated string")
+		}
+		if end != len(text)-1 {
+			return fail("unexpected %q after string", text[end+1:])
+		}
+		value, err := unquoteYAML(text)
+		if err != nil {
+			return fail("%v", err)
+		}
+		return &yamlNode{Line: line, Scalar: value}, nil
+	case '[', '{':
+		return fail("flow collections are not supported; write one item per line")
+	case '|', '>':
+		return fail("block scalars are not supported; quote the value instead")
+	case '&', '*', '!':
+		return fail("anchors, aliases and tags are not supported")
+	}
+	if _, _, ok := splitYAMLKey(text); ok {
+		return fail("put a nested mapping on its own lines")
+	}
+	if text == "~" || text == "null" {
+		text = ""
+	}
+	return &yamlNode{Line: line, Scalar: text}, nil
+}
+
+// yamlEscapes maps the letter of each short escape in a double-quoted scalar
+// to the character it stands for
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f",
+	'r': "\r", 'e': "\x1b", ' ': " ", '"': `"`, '/': "/", '\\': `\`,
+	'N': "\u0085", '_': "\u00a0", 'L': "\u2028", 'P': "\u2029",
+}
+
+// unquoteYAML returns the value of a single- or double-quoted scalar
+func unquoteYAML(text string) (string, error) {
+	body := text[1 : len(text)-1]
+	if text[0] == '\'' {
+		return strings.ReplaceAll(body, "''", "'"), nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' {
+			b.WriteByte(body[i])
+			continue
+		}
+		if i++; i >= len(body) {
+			return "", fmt.Errorf("unfinished escape")
+		}
+		if s, ok := yamlEscapes[body[i]]; ok {
+			b.WriteString(s)
+			continue
+		}
+
+		digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[body[i]]
+		if digits == 0 {
+			return "", fmt.Errorf("unknown escape \\%c", body[i])
+		}
+		if i+digits >= len(body) {
+			return "", fmt.Errorf("short escape \\%s", body[i:])
+		}
+		code, err := strconv.ParseUint(body[i+1:i+1+digits], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return "", fmt.Errorf("invalid escape \\%s", body[i:i+1+digits])
+		}
+		b.WriteRune(rune(code))
+		i += digits
+	}
+	return b.String(), nil
+}
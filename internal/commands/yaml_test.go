@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+// dumpYAML writes node in a compact form for comparisons
+func dumpYAML(node *yamlNode) string {
+	switch node.Kind {
+	case yamlSeq:
+		items := make([]string, len(node.Seq))
+		for i, item := range node.Seq {
+			items[i] = dumpYAML(item)
+		}
+		return "[" + strings.Join(items, " ") + "]"
+	case yamlMap:
+		pairs := make([]string, len(node.Map))
+		for i, pair := range node.Map {
+			pairs[i] = pair.Key + "=" + dumpYAML(pair.Value)
+		}
+		return "{" + strings.Join(pairs, " ") + "}"
+	default:
+		return "'" + node.Scalar + "'"
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "nested blocks",
+			content: "---\na:\n  b: 1\n  c:\n    - x\n    - y: 2\n      z: 3\nd: e\n",
+			want:    "{a={b='1' c=['x' {y='2' z='3'}]} d='e'}",
+		},
+		{
+			name:    "list at the key's indent",
+			content: "repos:\n- url: a\n- url: b\n",
+			want:    "{repos=[{url='a'} {url='b'}]}",
+		},
+		{
+			name:    "comments and empty values",
+			content: "# top\na: # none\nb: ~\nc: value # note\nd: a#b\n",
+			want:    "{a='' b='' c='value' d='a#b'}",
+		},
+		{
+			name:    "colons that don't end keys",
+			content: "url: git@host:org/repo.git\nweb: https://example.com/x\n",
+			want:    "{url='git@host:org/repo.git' web='https://example.com/x'}",
+		},
+		{
+			name:    "quoted scalars",
+			content: `a: "tab\there \"q\" # not a comment \u00e9"` + "\nb: 'it''s # kept'\n'c d': \"\\e\"\n",
+			want:    "{a='tab\there \"q\" # not a comment é' b='it's # kept' c d='\x1b'}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parseYAML(tt.content)
+			if err != nil {
+				t.Fatalf("parseYAML() error = %v", err)
+			}
+			if got := dumpYAML(node); got != tt.want {
+				t.Errorf("parseYAML() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAML_Errors(t *testing.T) {
+	tests := []struct {
+		content string
+		wantErr string
+	}{
+		{"a: [1, 2]", "flow collections"},
+		{"a: {b: 1}", "flow collections"},
+		{"a: |\n  text", "block scalars"},
+		{"a: &x 1", "anchors"},
+		{"a: 1\na: 2", `"a" appears twice`},
+		{"a: 1\n  b: 2", "unexpected indentation"},
+		{"a:\n\t- b", "tabs"},
+		{"a: \"open", "unterminated string"},
+		{"a: \"\\q\"", "unknown escape"},
+		{"a: 1\n---\nb: 2", "one document"},
+		{"- - x", "own lines"},
+		{"a: b: c", "nested mapping"},
+		{"just text", "expected a list item"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			_, err := parseYAML(tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.HasPrefix(err.Error(), "line ") {
+				t.Errorf("parseYAML(%q) error = %v, want line ...%s...", tt.content, err, tt.wantErr)
+			}
+		})
+	}
+}
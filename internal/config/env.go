@@ -3,20 +3,78 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 )
 
+// homeEnv is the environment variable that keeps everything gwtm stores in
+// one directory, overriding both the default install directory and the XDG
+// base directories
+const homeEnv = "GIT_WORKTREE_MANAGER_HOME"
+
+// xdgPlatform reports whether the platform follows the XDG base directory
+// specification
+var xdgPlatform = runtime.GOOS == "linux"
+
 // GetInstallDir returns the installation directory for git-worktree-manager
 // Respects GIT_WORKTREE_MANAGER_HOME environment variable, defaults to $HOME/.git-worktree-manager
 func GetInstallDir() string {
-	if customDir := os.Getenv("GIT_WORKTREE_MANAGER_HOME"); customDir != "" {
+	if customDir := os.Getenv(homeEnv); customDir != "" {
 		return customDir
 	}
 
+	return filepath.Join(homeDir(), ".git-worktree-manager")
+}
+
+// UsesXDG reports whether gwtm keeps its files in the XDG base directories
+// rather than the install directory: on Linux, unless GIT_WORKTREE_MANAGER_HOME is set
+func UsesXDG() bool {
+	return xdgPlatform && os.Getenv(homeEnv) == ""
+}
+
+// GetConfigDir returns the directory holding the user's settings and hooks:
+// $XDG_CONFIG_HOME/gwtm, or the install directory
+func GetConfigDir() string {
+	if !UsesXDG() {
+		return GetInstallDir()
+	}
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// GetStateDir returns the directory holding what gwtm records between runs,
+// such as the project registry: $XDG_STATE_HOME/gwtm, or the install directory
+func GetStateDir() string {
+	if !UsesXDG() {
+		return GetInstallDir()
+	}
+	return xdgDir("XDG_STATE_HOME", ".local", "state")
+}
+
+// GetCacheDir returns the directory holding gwtm's caches, such as the shared
+// object cache used by 'gwtm setup --cache': $XDG_CACHE_HOME/gwtm, or cache
+// in the install directory
+func GetCacheDir() string {
+	if !UsesXDG() {
+		return filepath.Join(GetInstallDir(), "cache")
+	}
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+// xdgDir returns gwtm's directory under the base directory named by env, or
+// under fallback in the home directory when env is unset. The specification
+// says relative paths must be ignored, so they count as unset.
+func xdgDir(env string, fallback ...string) string {
+	base := os.Getenv(env)
+	if !filepath.IsAbs(base) {
+		base = filepath.Join(append([]string{homeDir()}, fallback...)...)
+	}
+	return filepath.Join(base, "gwtm")
+}
+
+func homeDir() string {
 	home := os.Getenv("HOME")
 	if home == "" {
 		// Fallback for Windows
 		home = os.Getenv("USERPROFILE")
 	}
-
-	return filepath.Join(home, ".git-worktree-manager")
+	return home
 }
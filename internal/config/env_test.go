@@ -63,3 +63,72 @@ func TestGetInstallDir(t *testing.T) {
 		})
 	}
 }
+
+// withXDGPlatform makes the test behave as on a platform that does, or does
+// not, follow the XDG base directories
+func withXDGPlatform(t *testing.T, xdg bool) {
+	t.Helper()
+	orig := xdgPlatform
+	xdgPlatform = xdg
+	t.Cleanup(func() { xdgPlatform = orig })
+}
+
+func TestXDGDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GIT_WORKTREE_MANAGER_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	t.Setenv("XDG_STATE_HOME", "relative/state") // Relative paths are ignored
+	t.Setenv("XDG_CACHE_HOME", "")
+	legacy := filepath.Join(home, ".git-worktree-manager")
+
+	tests := []struct {
+		name      string
+		xdg       bool
+		override  string
+		wantDirs  [3]string // Config, state and cache directories
+		wantFiles [3]string // Config file, registry and hooks directory
+	}{
+		{
+			name:      "XDG platform",
+			xdg:       true,
+			wantDirs:  [3]string{"/xdg/config/gwtm", filepath.Join(home, ".local", "state", "gwtm"), filepath.Join(home, ".cache", "gwtm")},
+			wantFiles: [3]string{"/xdg/config/gwtm/config.toml", filepath.Join(home, ".local", "state", "gwtm", "projects.json"), "/xdg/config/gwtm/hooks"},
+		},
+		{
+			name:      "override keeps everything in one directory",
+			xdg:       true,
+			override:  "/custom",
+			wantDirs:  [3]string{"/custom", "/custom", "/custom/cache"},
+			wantFiles: [3]string{"/custom/config.toml", "/custom/projects.json", "/custom/hooks"},
+		},
+		{
+			name:      "other platforms use the install directory",
+			xdg:       false,
+			wantDirs:  [3]string{legacy, legacy, filepath.Join(legacy, "cache")},
+			wantFiles: [3]string{filepath.Join(legacy, "config.toml"), filepath.Join(legacy, "projects.json"), filepath.Join(legacy, "hooks")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withXDGPlatform(t, tt.xdg)
+			t.Setenv("GIT_WORKTREE_MANAGER_HOME", tt.override)
+
+			gotDirs := [3]string{GetConfigDir(), GetStateDir(), GetCacheDir()}
+			for i := range gotDirs {
+				if filepath.ToSlash(gotDirs[i]) != filepath.ToSlash(tt.wantDirs[i]) {
+					t.Errorf("directories = %v, want %v", gotDirs, tt.wantDirs)
+					break
+				}
+			}
+			gotFiles := [3]string{GetConfigPath(), GetRegistryPath(), GetHooksDir()}
+			for i := range gotFiles {
+				if filepath.ToSlash(gotFiles[i]) != filepath.ToSlash(tt.wantFiles[i]) {
+					t.Errorf("files = %v, want %v", gotFiles, tt.wantFiles)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Move is a file or directory that moves from the install directory to its
+// XDG base directory
+type Move struct {
+	From string
+	To   string
+}
+
+// LegacyMoves returns what still has to move from the install directory,
+// where gwtm kept everything before it followed the XDG base directories.
+// Items whose new location already exists stay where they are.
+func LegacyMoves() []Move {
+	if !UsesXDG() {
+		return nil
+	}
+
+	legacy := GetInstallDir()
+	candidates := []Move{
+		{From: filepath.Join(legacy, "config.toml"), To: GetConfigPath()},
+		{From: filepath.Join(legacy, "hooks"), To: GetHooksDir()},
+		{From: filepath.Join(legacy, "projects.json"), To: GetRegistryPath()},
+		{From: filepath.Join(legacy, "cache"), To: GetCacheDir()},
+	}
+
+	var moves []Move
+	for _, m := range candidates {
+		if info, err := os.Lstat(m.From); err != nil || info.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		if _, err := os.Lstat(m.To); err == nil {
+			continue
+		}
+		moves = append(moves, m)
+	}
+	return moves
+}
+
+// MigrateLegacyDirs performs the moves LegacyMoves returns and reports the
+// ones that succeeded. The cache is replaced by a link to its new location,
+// because projects set up with --cache borrow objects from it by absolute path.
+func MigrateLegacyDirs() ([]Move, error) {
+	var done []Move
+	var errs []error
+	for _, m := range LegacyMoves() {
+		if err := os.MkdirAll(filepath.Dir(m.To), 0755); err != nil {
+			errs = append(errs, fmt.Errorf("failed to create %s: %w", filepath.Dir(m.To), err))
+			continue
+		}
+		if err := os.Rename(m.From, m.To); err != nil {
+			errs = append(errs, fmt.Errorf("failed to move %s to %s: %w", m.From, m.To, err))
+			continue
+		}
+		if m.To == GetCacheDir() {
+			if err := os.Symlink(m.To, m.From); err != nil {
+				errs = append(errs, fmt.Errorf("failed to link %s to %s: %w", m.From, m.To, err))
+			}
+		}
+		done = append(done, m)
+	}
+	return done, errors.Join(errs...)
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateLegacyDirs(t *testing.T) {
+	withXDGPlatform(t, true)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GIT_WORKTREE_MANAGER_HOME", "")
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME"} {
+		t.Setenv(env, "")
+	}
+
+	legacy := GetInstallDir()
+	os.MkdirAll(filepath.Join(legacy, "hooks"), 0755)
+	os.MkdirAll(filepath.Join(legacy, "cache", "objects"), 0755)
+	os.WriteFile(filepath.Join(legacy, "hooks", "post-create"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(legacy, "config.toml"), []byte("protocol = \"https\"\n"), 0644)
+	os.WriteFile(filepath.Join(legacy, "projects.json"), []byte("{}\n"), 0644)
+	os.WriteFile(filepath.Join(legacy, "gwtm"), []byte("binary"), 0755)
+
+	// A file already in its new location wins over the legacy one
+	os.MkdirAll(GetStateDir(), 0755)
+	os.WriteFile(GetRegistryPath(), []byte(`{"projects": []}`), 0644)
+
+	if got := len(LegacyMoves()); got != 3 {
+		t.Fatalf("LegacyMoves() = %d moves, want 3", got)
+	}
+
+	moved, err := MigrateLegacyDirs()
+	if err != nil {
+		t.Fatalf("MigrateLegacyDirs() error = %v", err)
+	}
+	if len(moved) != 3 {
+		t.Errorf("MigrateLegacyDirs() moved %v, want config.toml, hooks and cache", moved)
+	}
+
+	for _, path := range []string{GetConfigPath(), filepath.Join(GetHooksDir(), "post-create"), filepath.Join(GetCacheDir(), "objects")} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s was not moved: %v", path, err)
+		}
+	}
+	if data, _ := os.ReadFile(GetRegistryPath()); string(data) != `{"projects": []}` {
+		t.Errorf("registry = %s, want the file already in the new location", data)
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "gwtm")); err != nil {
+		t.Error("the binary should stay in the install directory")
+	}
+
+	// Projects borrow from the cache by absolute path, so the old path still leads to it
+	if _, err := os.Stat(filepath.Join(legacy, "cache", "objects")); err != nil {
+		t.Errorf("old cache path no longer resolves: %v", err)
+	}
+
+	if moves := LegacyMoves(); len(moves) != 0 {
+		t.Errorf("LegacyMoves() after migrating = %v, want none", moves)
+	}
+}
@@ -14,3 +14,23 @@ func GetBinaryPath() string {
 	}
 	return filepath.Join(installDir, name)
 }
+
+// GetHooksDir returns the directory holding the user's own lifecycle hooks,
+// which run for every project in addition to a repository's .gwtm/hooks
+func GetHooksDir() string {
+	return filepath.Join(GetConfigDir(), "hooks")
+}
+
+// GetRegistryPath returns the file recording the projects gwtm manages
+func GetRegistryPath() string {
+	return filepath.Join(GetStateDir(), "projects.json")
+}
+
+// GetConfigPath returns the user's settings file
+func GetConfigPath() string {
+	return filepath.Join(GetConfigDir(), "config.toml")
+}
+
+// ProjectConfigFile is the per-project settings file in the project root,
+// beside .bare and outside every worktree, so it is never committed
+const ProjectConfigFile = ".gwtm.toml"
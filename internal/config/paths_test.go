@@ -24,6 +24,50 @@ func TestGetBinaryPath(t *testing.T) {
 	}
 }
 
+func TestGetCacheDir(t *testing.T) {
+	installDir := t.TempDir()
+	os.Setenv("GIT_WORKTREE_MANAGER_HOME", installDir)
+	defer os.Unsetenv("GIT_WORKTREE_MANAGER_HOME")
+
+	expected := filepath.Join(installDir, "cache")
+	if got := GetCacheDir(); got != expected {
+		t.Errorf("GetCacheDir() = %v, want %v", got, expected)
+	}
+}
+
+func TestGetHooksDir(t *testing.T) {
+	installDir := t.TempDir()
+	os.Setenv("GIT_WORKTREE_MANAGER_HOME", installDir)
+	defer os.Unsetenv("GIT_WORKTREE_MANAGER_HOME")
+
+	expected := filepath.Join(installDir, "hooks")
+	if got := GetHooksDir(); got != expected {
+		t.Errorf("GetHooksDir() = %v, want %v", got, expected)
+	}
+}
+
+func TestGetRegistryPath(t *testing.T) {
+	installDir := t.TempDir()
+	os.Setenv("GIT_WORKTREE_MANAGER_HOME", installDir)
+	defer os.Unsetenv("GIT_WORKTREE_MANAGER_HOME")
+
+	expected := filepath.Join(installDir, "projects.json")
+	if got := GetRegistryPath(); got != expected {
+		t.Errorf("GetRegistryPath() = %v, want %v", got, expected)
+	}
+}
+
+func TestGetConfigPath(t *testing.T) {
+	installDir := t.TempDir()
+	os.Setenv("GIT_WORKTREE_MANAGER_HOME", installDir)
+	defer os.Unsetenv("GIT_WORKTREE_MANAGER_HOME")
+
+	expected := filepath.Join(installDir, "config.toml")
+	if got := GetConfigPath(); got != expected {
+		t.Errorf("GetConfigPath() = %v, want %v", got, expected)
+	}
+}
+
 func TestPathJoinCrossPlatform(t *testing.T) {
 	tests := []struct {
 		name     string
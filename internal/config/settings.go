@@ -0,0 +1,581 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+)
+
+// Kind is the type of a setting's value
+type Kind int
+
+const (
+	String Kind = iota
+	Bool
+	Int
+	List // Several values: a repeated git config key or a TOML array
+)
+
+// Key describes a setting gwtm understands
+type Key struct {
+	Name    string // Dotted name, e.g. branch.template; the prefix for families
+	Kind    Kind
+	Default string   // Value when no layer sets the key
+	Choices []string // Allowed values; anything when empty
+	Family  bool     // Every <Name>.<something> is a setting, e.g. host.<alias>
+	Help    string
+}
+
+// Keys lists every setting, in the order 'gwtm config list' shows them
+var Keys = []Key{
+	{Name: "defaultHost", Default: "github.com", Help: "Host for the org/repo shorthand"},
+	{Name: "protocol", Default: "ssh", Choices: []string{"ssh", "https"}, Help: "Protocol for shorthand clone URLs"},
+	{Name: "host", Family: true, Help: "Alias for a host or URL prefix, used as <alias>:<path>"},
+	{Name: "forge", Choices: []string{"github", "gitlab"}, Help: "Forge hosting origin, for --pr; detected from the origin URL when unset"},
+	{Name: "branch.template", Default: "{type}/{ticket}-{slug}", Help: "Branch name built from --type, --ticket and a description"},
+	{Name: "branch.pattern", Help: "Regular expression every new branch name must match"},
+	{Name: "branch.prefix", Kind: List, Help: "Prefixes new branch names must start with"},
+	{Name: "branch.maxLength", Kind: Int, Help: "Longest allowed branch name"},
+	{Name: "include", Kind: List, Help: "Patterns of untracked files to bring into new worktrees, after .worktreeinclude"},
+	{Name: "includeMode", Default: "copy", Choices: []string{"copy", "symlink", "reflink"}, Help: "How included local files are brought into new worktrees"},
+	{Name: "submodules", Kind: Bool, Default: "false", Help: "Initialise submodules in new worktrees"},
+	{Name: "hooks", Kind: Bool, Default: "true", Help: "Run lifecycle hooks"},
+	{Name: "hooks.trustRepo", Kind: Bool, Default: "false", Help: "Run hooks from the repository's .gwtm/hooks without asking"},
+	{Name: "referenceCache", Kind: Bool, Default: "false", Help: "Share objects through the cache when setting up projects"},
+	{Name: "maintenance", Kind: Bool, Default: "false", Help: "Schedule background maintenance for new projects"},
+	{Name: "syncMode", Default: "ff", Choices: []string{"ff", "rebase"}, Help: "How 'gwtm sync' updates worktrees"},
+	{Name: "alias", Family: true, Help: "Command alias or multi-step macro, as alias.<name>; see 'gwtm help' for the aliases in effect"},
+	{Name: "profile", Default: DefaultProfile, Help: "Profile of git settings applied at setup and by 'gwtm config apply'"},
+	{Name: "profiles", Family: true, Help: "Git setting in a named profile, as profiles.<profile>.<git key>"},
+}
+
+// DefaultProfile is the profile of git settings used unless another is chosen
+const DefaultProfile = "default"
+
+// LookupKey returns the description of the setting name, matched without
+// regard to case the way git config matches names
+func LookupKey(name string) (Key, bool) {
+	for _, k := range Keys {
+		if strings.EqualFold(k.Name, name) && !k.Family {
+			return k, true
+		}
+		if k.Family && len(name) > len(k.Name)+1 && strings.EqualFold(name[:len(k.Name)+1], k.Name+".") {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// CanonicalKey returns name as gwtm spells it: the schema's spelling for
+// known keys, with the last part of family members lower-cased the way git
+// reports variable names. Unknown names are returned unchanged.
+func CanonicalKey(name string) string {
+	name = strings.TrimPrefix(name, "gwtm.")
+	k, ok := LookupKey(name)
+	switch {
+	case !ok:
+		return name
+	case k.Family:
+		member := name[len(k.Name)+1:]
+		dot := strings.LastIndexByte(member, '.') + 1
+		return k.Name + "." + member[:dot] + strings.ToLower(member[dot:])
+	default:
+		return k.Name
+	}
+}
+
+// keyParts splits key into the parts of a TOML key: the schema name's parts,
+// then a family member as a single part, however many dots it has. A profile
+// member is the profile's name followed by the git key's section, any
+// subsection and variable.
+func keyParts(key string) []string {
+	k, ok := LookupKey(key)
+	if !ok || !k.Family {
+		return strings.Split(key, ".")
+	}
+
+	parts := strings.Split(k.Name, ".")
+	member := key[len(k.Name)+1:]
+	if k.Name != "profiles" {
+		return append(parts, member)
+	}
+
+	profile, gitKey, ok := strings.Cut(member, ".")
+	if !ok {
+		return append(parts, member)
+	}
+	parts = append(parts, profile)
+	first, last := strings.IndexByte(gitKey, '.'), strings.LastIndexByte(gitKey, '.')
+	if first < 0 {
+		return append(parts, gitKey)
+	}
+	parts = append(parts, gitKey[:first])
+	if first < last {
+		parts = append(parts, gitKey[first+1:last])
+	}
+	return append(parts, gitKey[last+1:])
+}
+
+// EnvName returns the environment variable that sets the setting name:
+// GWTM_ followed by the name in upper snake case, e.g. GWTM_BRANCH_MAX_LENGTH.
+// The dots in a family member become double underscores, so
+// profiles.work.push.default is GWTM_PROFILES_WORK__PUSH__DEFAULT.
+func EnvName(name string) string {
+	if k, ok := LookupKey(name); ok && k.Family {
+		member := name[len(k.Name)+1:]
+		return EnvName(k.Name) + "_" + strings.ToUpper(strings.ReplaceAll(member, ".", "__"))
+	}
+
+	var b strings.Builder
+	b.WriteString("GWTM_")
+	var prev rune
+	for _, r := range name {
+		switch {
+		case r == '.' || r == '-':
+			b.WriteByte('_')
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			b.WriteByte('_')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(unicode.ToUpper(r))
+		}
+		prev = r
+	}
+	return b.String()
+}
+
+// Validate checks values for the setting name and returns the canonical name
+func Validate(name string, values []string) (string, error) {
+	k, ok := LookupKey(strings.TrimPrefix(name, "gwtm."))
+	if !ok {
+		return "", fmt.Errorf("unknown setting %q", name)
+	}
+	if len(values) == 0 {
+		return "", fmt.Errorf("%s needs a value", name)
+	}
+	if k.Kind != List && len(values) > 1 {
+		return "", fmt.Errorf("%s takes a single value", name)
+	}
+
+	for _, v := range values {
+		switch {
+		case k.Kind == Bool:
+			if _, ok := ParseBool(v); !ok {
+				return "", fmt.Errorf("%s must be true or false, not %q", name, v)
+			}
+		case k.Kind == Int:
+			if n, err := strconv.Atoi(v); err != nil || n < 0 {
+				return "", fmt.Errorf("%s must be a whole number, not %q", name, v)
+			}
+		case len(k.Choices) > 0 && !slices.Contains(k.Choices, v):
+			return "", fmt.Errorf("%s must be one of %s, not %q", name, strings.Join(k.Choices, ", "), v)
+		}
+	}
+	return CanonicalKey(name), nil
+}
+
+// ParseBool interprets a boolean the way git config does
+func ParseBool(s string) (value, ok bool) {
+	switch strings.ToLower(s) {
+	case "true", "yes", "on", "1":
+		return true, true
+	case "false", "no", "off", "0":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// Layer is one source of settings. Later layers override earlier ones.
+type Layer string
+
+const (
+	LayerDefault   Layer = "default"    // Built-in defaults
+	LayerUser      Layer = "user"       // The user's config.toml
+	LayerGitGlobal Layer = "git-global" // gwtm.* in the system and global git config
+	LayerProject   Layer = "project"    // The project's .gwtm.toml
+	LayerGitLocal  Layer = "git-local"  // gwtm.* in the project's .bare/config
+	LayerEnv       Layer = "env"        // GWTM_* environment variables
+	LayerFlag      Layer = "flag"       // Command-line flags
+)
+
+// Layers lists the layers from lowest to highest precedence
+var Layers = []Layer{LayerDefault, LayerUser, LayerGitGlobal, LayerProject, LayerGitLocal, LayerEnv, LayerFlag}
+
+// Origin is where a setting's value came from
+type Origin struct {
+	Layer  Layer
+	Source string // File, variable or flag that set the value; empty for defaults
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return string(o.Layer)
+	}
+	return string(o.Layer) + ":" + o.Source
+}
+
+// Value is a setting's effective value
+type Value struct {
+	Key    string
+	Values []string // Several only for List settings
+	Origin Origin
+}
+
+// String returns the value, joining list values with commas
+func (v Value) String() string {
+	return strings.Join(v.Values, ",")
+}
+
+// layer holds the settings one source provides
+type layer struct {
+	origin  Origin
+	values  map[string][]string
+	keys    []string          // Keys in the order the source listed them
+	sources map[string]string // Per-key sources, for layers of variables or flags
+}
+
+func (l *layer) add(key string, values ...string) {
+	key = CanonicalKey(key)
+	if _, ok := l.values[key]; !ok {
+		l.keys = append(l.keys, key)
+	}
+	l.values[key] = values
+}
+
+// Settings are gwtm's settings, layered from every source
+type Settings struct {
+	layers []*layer // Lowest precedence first
+}
+
+// Load reads the settings that apply in the project at root, or outside any
+// project when root is empty: defaults, then the user's config.toml, then
+// gwtm.* in system and global git config, then the project's .gwtm.toml, then
+// gwtm.* in the project's git config, then GWTM_* environment variables.
+// Flags are layered on top with SetFlag. Layers that cannot be read are
+// skipped and reported in the error; the rest still apply.
+func Load(root string) (*Settings, error) {
+	s := &Settings{}
+	var errs []error
+
+	defaults := s.addLayer(Origin{Layer: LayerDefault})
+	for _, k := range Keys {
+		if k.Default != "" {
+			defaults.add(k.Name, k.Default)
+		}
+	}
+
+	if err := s.loadFile(LayerUser, GetConfigPath()); err != nil {
+		errs = append(errs, err)
+	}
+
+	global := s.addLayer(Origin{Layer: LayerGitGlobal})
+	for _, scope := range []string{"system", "global"} {
+		if err := loadGitConfig(global, "", scope); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if root != "" {
+		if err := s.loadFile(LayerProject, filepath.Join(root, ProjectConfigFile)); err != nil {
+			errs = append(errs, err)
+		}
+		local := s.addLayer(Origin{Layer: LayerGitLocal, Source: filepath.Join(root, ".bare", "config")})
+		if err := loadGitConfig(local, root, "local"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := s.loadEnv(); err != nil {
+		errs = append(errs, err)
+	}
+	s.addLayer(Origin{Layer: LayerFlag})
+
+	return s, errors.Join(errs...)
+}
+
+func (s *Settings) addLayer(origin Origin) *layer {
+	l := &layer{origin: origin, values: map[string][]string{}, sources: map[string]string{}}
+	s.layers = append(s.layers, l)
+	return l
+}
+
+// loadFile adds the settings file at path as a layer; a missing file is empty
+func (s *Settings) loadFile(name Layer, path string) error {
+	l := s.addLayer(Origin{Layer: name, Source: path})
+	values, err := readSettingsFile(path)
+	if err != nil {
+		return err
+	}
+	for _, e := range values.entries {
+		l.add(e.Key, e.Values...)
+	}
+	return nil
+}
+
+// readSettingsFile parses the settings file at path; a missing file is empty
+func readSettingsFile(path string) (*tomlDoc, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	doc, err := parseTOML(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// loadGitConfig adds the gwtm.* entries of one git config scope to l. Repeated
+// keys collect every value, the way git reports multi-valued keys.
+func loadGitConfig(l *layer, dir, scope string) error {
+	entries, err := git.NewClient(dir).ListConfigScope(scope, `^gwtm\.`)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for _, e := range entries {
+		key := CanonicalKey(e.Key)
+		if seen[key] {
+			l.values[key] = append(l.values[key], e.Value)
+			continue
+		}
+		seen[key] = true
+		l.add(key, e.Value)
+	}
+	return nil
+}
+
+// loadEnv adds a layer for GWTM_* variables; list settings split on commas
+// and double underscores in a family member stand for dots. Variables that
+// can't name a setting are skipped and reported in the error.
+func (s *Settings) loadEnv() error {
+	l := s.addLayer(Origin{Layer: LayerEnv})
+	var errs []error
+	for _, k := range Keys {
+		if k.Family {
+			prefix := EnvName(k.Name) + "_"
+			for _, kv := range os.Environ() {
+				name, value, _ := strings.Cut(kv, "=")
+				member, ok := strings.CutPrefix(name, prefix)
+				if !ok || member == "" || value == "" {
+					continue
+				}
+				member = strings.ToLower(strings.ReplaceAll(member, "__", "."))
+				// A profile member is <profile>.<section>.<variable> at least
+				if k.Name == "profiles" && strings.Count(member, ".") < 2 {
+					errs = append(errs, fmt.Errorf("%s: separate the profile, section and variable with __, e.g. GWTM_PROFILES_WORK__PUSH__DEFAULT", name))
+					continue
+				}
+				key := k.Name + "." + member
+				l.add(key, value)
+				l.sources[key] = name
+			}
+			continue
+		}
+
+		value := os.Getenv(EnvName(k.Name))
+		if value == "" {
+			continue
+		}
+		if k.Kind == List {
+			l.add(k.Name, strings.Split(value, ",")...)
+		} else {
+			l.add(k.Name, value)
+		}
+		l.sources[k.Name] = EnvName(k.Name)
+	}
+	return errors.Join(errs...)
+}
+
+// SetFlag layers the value given by a command-line flag over every other source
+func (s *Settings) SetFlag(key, flag string, values ...string) {
+	l := s.layers[len(s.layers)-1]
+	l.add(key, values...)
+	l.sources[CanonicalKey(key)] = flag
+}
+
+// Lookup returns the effective value of key and whether any layer sets it
+func (s *Settings) Lookup(key string) (Value, bool) {
+	key = CanonicalKey(key)
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		l := s.layers[i]
+		if values, ok := l.values[key]; ok {
+			origin := l.origin
+			if source, ok := l.sources[key]; ok {
+				origin.Source = source
+			}
+			return Value{Key: key, Values: values, Origin: origin}, true
+		}
+	}
+	return Value{Key: key}, false
+}
+
+// Get returns the value of key, or its last value for lists; empty when unset
+func (s *Settings) Get(key string) string {
+	v, _ := s.Lookup(key)
+	if len(v.Values) == 0 {
+		return ""
+	}
+	return v.Values[len(v.Values)-1]
+}
+
+// GetAll returns every value of the list setting key
+func (s *Settings) GetAll(key string) []string {
+	v, _ := s.Lookup(key)
+	return v.Values
+}
+
+// Bool returns the boolean setting key, falling back to its default when the
+// value is not a recognised boolean
+func (s *Settings) Bool(key string) bool {
+	if value, ok := ParseBool(s.Get(key)); ok {
+		return value
+	}
+	k, _ := LookupKey(key)
+	value, _ := ParseBool(k.Default)
+	return value
+}
+
+// Family returns every member of the family prefix, keyed by the part of
+// the name after the prefix
+func (s *Settings) Family(prefix string) map[string]string {
+	members := map[string]string{}
+	for _, v := range s.List() {
+		if member, ok := strings.CutPrefix(v.Key, prefix+"."); ok {
+			members[member] = v.Values[len(v.Values)-1]
+		}
+	}
+	return members
+}
+
+// Profile returns the git settings of the named profile: gwtm's built-in
+// settings, overridden by the profile's profiles.<name>.<git key> entries. An
+// empty value drops the key, so gwtm leaves it alone. The default profile
+// exists even when nothing configures it.
+func (s *Settings) Profile(name string) (map[string]string, error) {
+	profile := git.DefaultWorktreeSettings()
+	found := name == DefaultProfile
+	for member, value := range s.Family("profiles") {
+		profileName, key, ok := strings.Cut(member, ".")
+		if !ok || profileName != name {
+			continue
+		}
+		found = true
+		key = gitConfigKey(key)
+		if value == "" {
+			delete(profile, key)
+		} else {
+			profile[key] = value
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("unknown profile %q (known profiles: %s)", name, strings.Join(s.Profiles(), ", "))
+	}
+	return profile, nil
+}
+
+// Profiles returns the names of every profile, sorted
+func (s *Settings) Profiles() []string {
+	names := []string{DefaultProfile}
+	for member := range s.Family("profiles") {
+		if name, _, ok := strings.Cut(member, "."); ok && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// gitConfigKey spells a git config key the way git reports it: section and
+// variable names lower-cased, any subsection left alone
+func gitConfigKey(key string) string {
+	first, last := strings.IndexByte(key, '.'), strings.LastIndexByte(key, '.')
+	if first < 0 {
+		return key
+	}
+	return strings.ToLower(key[:first]) + key[first:last+1] + strings.ToLower(key[last+1:])
+}
+
+// List returns the effective value of every setting that has one, known
+// settings in schema order followed by any others alphabetically
+func (s *Settings) List() []Value {
+	var keys []string
+	for _, l := range s.layers {
+		for _, key := range l.keys {
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	rank := func(key string) int {
+		k, ok := LookupKey(key)
+		if !ok {
+			return len(Keys)
+		}
+		return slices.IndexFunc(Keys, func(other Key) bool { return other.Name == k.Name })
+	}
+	slices.SortStableFunc(keys, func(a, b string) int {
+		if ra, rb := rank(a), rank(b); ra != rb {
+			return ra - rb
+		}
+		return strings.Compare(a, b)
+	})
+
+	values := make([]Value, 0, len(keys))
+	for _, key := range keys {
+		v, _ := s.Lookup(key)
+		values = append(values, v)
+	}
+	return values
+}
+
+// SetInFile sets key to values in the settings file at path, creating the file
+// when needed and leaving the rest of it as it was
+func SetInFile(path, key string, values []string) error {
+	key, err := Validate(key, values)
+	if err != nil {
+		return err
+	}
+	doc, err := readSettingsFile(path)
+	if err != nil {
+		return err
+	}
+
+	k, _ := LookupKey(key)
+	doc.set(key, formatTOMLValue(k.Kind, values))
+	return writeSettingsFile(path, doc)
+}
+
+// UnsetInFile removes key from the settings file at path and reports whether
+// the file had it
+func UnsetInFile(path, key string) (bool, error) {
+	doc, err := readSettingsFile(path)
+	if err != nil {
+		return false, err
+	}
+	if !doc.unset(key) {
+		return false, nil
+	}
+	return true, writeSettingsFile(path, doc)
+}
+
+func writeSettingsFile(path string, doc *tomlDoc) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(doc.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
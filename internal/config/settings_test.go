@@ -0,0 +1,269 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lucasmodrich/git-worktree-manager/internal/git"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"protocol":                   "GWTM_PROTOCOL",
+		"defaultHost":                "GWTM_DEFAULT_HOST",
+		"branch.maxLength":           "GWTM_BRANCH_MAX_LENGTH",
+		"host":                       "GWTM_HOST",
+		"host.gl":                    "GWTM_HOST_GL",
+		"profiles.work.push.default": "GWTM_PROFILES_WORK__PUSH__DEFAULT",
+	}
+	for name, want := range tests {
+		if got := EnvName(name); got != want {
+			t.Errorf("EnvName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCanonicalKey(t *testing.T) {
+	tests := map[string]string{
+		"branch.maxlength":  "branch.maxLength",
+		"gwtm.DEFAULTHOST":  "defaultHost",
+		"host.GL":           "host.gl",
+		"gwtm.host.gl":      "host.gl",
+		"something.unknown": "something.unknown",
+		"host":              "host",
+		"profiles.Team.url.git@github.com:.insteadOf": "profiles.Team.url.git@github.com:.insteadof",
+	}
+	for name, want := range tests {
+		if got := CanonicalKey(name); got != want {
+			t.Errorf("CanonicalKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    string
+		wantErr string
+	}{
+		{name: "gwtm.protocol", values: []string{"https"}, want: "protocol"},
+		{name: "protocol", values: []string{"ftp"}, wantErr: "one of ssh, https"},
+		{name: "hooks", values: []string{"off"}, want: "hooks"},
+		{name: "hooks", values: []string{"maybe"}, wantErr: "true or false"},
+		{name: "branch.maxlength", values: []string{"-1"}, wantErr: "whole number"},
+		{name: "branch.prefix", values: []string{"feat/", "fix/"}, want: "branch.prefix"},
+		{name: "defaultHost", values: []string{"a", "b"}, wantErr: "single value"},
+		{name: "host.GL", values: []string{"gitlab.com"}, want: "host.gl"},
+		{name: "host", values: []string{"gitlab.com"}, wantErr: "unknown setting"},
+		{name: "colour", values: []string{"red"}, wantErr: "unknown setting"},
+		{name: "protocol", values: nil, wantErr: "needs a value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"="+strings.Join(tt.values, ","), func(t *testing.T) {
+			got, err := Validate(tt.name, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Validate() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Validate() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// isolateSettings points every settings source at an empty temporary location
+func isolateSettings(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(tmpDir, ".gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_WORKTREE_MANAGER_HOME", filepath.Join(tmpDir, "home"))
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); strings.HasPrefix(name, "GWTM_") {
+			t.Setenv(name, "")
+		}
+	}
+	return tmpDir
+}
+
+func TestLoad(t *testing.T) {
+	tmpDir := isolateSettings(t)
+
+	root := filepath.Join(tmpDir, "project")
+	if _, _, err := git.NewClient(tmpDir).ExecGit("init", "--bare", filepath.Join(root, ".bare")); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(root, ".git"), []byte("gitdir: ./.bare"), 0644)
+
+	userFile := GetConfigPath()
+	os.MkdirAll(filepath.Dir(userFile), 0755)
+	os.WriteFile(userFile, []byte("protocol = \"https\"\ndefaultHost = \"gitlab.com\"\n[branch]\ntemplate = \"user\"\n"), 0644)
+
+	global := git.NewClient(tmpDir)
+	global.ExecGit("config", "--global", "gwtm.defaultHost", "git.example.com")
+	global.ExecGit("config", "--global", "gwtm.host.GL", "gitlab.example.com")
+
+	projectFile := filepath.Join(root, ProjectConfigFile)
+	os.WriteFile(projectFile, []byte("branch.template = \"project\"\nbranch.prefix = [\"feat/\"]\n"), 0644)
+
+	local := git.NewClient(root)
+	local.ExecGit("config", "--add", "gwtm.branch.prefix", "fix/")
+	local.ExecGit("config", "--add", "gwtm.branch.prefix", "chore/")
+	local.ExecGit("config", "gwtm.syncMode", "rebase")
+
+	t.Setenv("GWTM_SYNC_MODE", "ff")
+	t.Setenv("GWTM_HOST_GH", "github.example.com")
+
+	settings, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	settings.SetFlag("submodules", "--submodules", "true")
+
+	tests := []struct {
+		key        string
+		want       string
+		wantOrigin string
+	}{
+		{"protocol", "https", "user:" + userFile},
+		{"defaultHost", "git.example.com", "git-global"},
+		{"branch.template", "project", "project:" + projectFile},
+		{"branch.prefix", "fix/,chore/", "git-local:" + filepath.Join(root, ".bare", "config")},
+		{"syncMode", "ff", "env:GWTM_SYNC_MODE"},
+		{"submodules", "true", "flag:--submodules"},
+		{"hooks", "true", "default"},
+		{"host.gl", "gitlab.example.com", "git-global"},
+		{"host.gh", "github.example.com", "env:GWTM_HOST_GH"},
+	}
+	for _, tt := range tests {
+		v, ok := settings.Lookup(tt.key)
+		if !ok || v.String() != tt.want || v.Origin.String() != tt.wantOrigin {
+			t.Errorf("Lookup(%q) = %q from %s, want %q from %s", tt.key, v.String(), v.Origin, tt.want, tt.wantOrigin)
+		}
+	}
+
+	if _, ok := settings.Lookup("branch.pattern"); ok {
+		t.Error("Lookup(branch.pattern) found a value, want none")
+	}
+	if !settings.Bool("submodules") || !settings.Bool("hooks") || settings.Bool("maintenance") {
+		t.Error("Bool() did not follow the layers and defaults")
+	}
+	if hosts := settings.Family("host"); len(hosts) != 2 || hosts["gl"] != "gitlab.example.com" {
+		t.Errorf("Family(host) = %v, want gl and gh", hosts)
+	}
+}
+
+func TestLoad_BrokenFile(t *testing.T) {
+	isolateSettings(t)
+	os.MkdirAll(filepath.Dir(GetConfigPath()), 0755)
+	os.WriteFile(GetConfigPath(), []byte("protocol = https\n"), 0644)
+	t.Setenv("GWTM_PROTOCOL", "ssh")
+
+	settings, err := Load("")
+	if err == nil || !strings.Contains(err.Error(), GetConfigPath()) {
+		t.Errorf("Load() error = %v, want it to name the broken file", err)
+	}
+	if got := settings.Get("protocol"); got != "ssh" {
+		t.Errorf("Get(protocol) = %q, want the other layers to still apply", got)
+	}
+}
+
+func TestLoad_EnvFamilyMembers(t *testing.T) {
+	isolateSettings(t)
+	t.Setenv("GWTM_PROFILES_WORK__PUSH__DEFAULT", "upstream")
+	t.Setenv("GWTM_ALIAS_CO_PR", "new-branch --pr")
+	t.Setenv("GWTM_PROFILES_WORK_PULL_REBASE", "true")
+
+	settings, err := Load("")
+	if err == nil || !strings.Contains(err.Error(), "GWTM_PROFILES_WORK_PULL_REBASE") {
+		t.Errorf("Load() error = %v, want it to name the variable without __", err)
+	}
+
+	v, ok := settings.Lookup("profiles.work.push.default")
+	if !ok || v.String() != "upstream" || v.Origin.String() != "env:GWTM_PROFILES_WORK__PUSH__DEFAULT" {
+		t.Errorf("Lookup(profiles.work.push.default) = %q from %s", v.String(), v.Origin)
+	}
+	if got := settings.Get("alias.co_pr"); got != "new-branch --pr" {
+		t.Errorf("Get(alias.co_pr) = %q, want new-branch --pr", got)
+	}
+	if profile, err := settings.Profile("work"); err != nil || profile["push.default"] != "upstream" || profile["pull.rebase"] != "" {
+		t.Errorf("Profile(work) = %v, %v", profile, err)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	tmpDir := isolateSettings(t)
+	os.MkdirAll(filepath.Dir(GetConfigPath()), 0755)
+	os.WriteFile(GetConfigPath(), []byte(`[profiles.norebase]
+branch.autoSetupRebase = "never"
+push.default = ""
+"url.git@github.com:.insteadOf" = "https://github.com/"
+`), 0644)
+	git.NewClient(tmpDir).ExecGit("config", "--global", "gwtm.profiles.default.pull.rebase", "true")
+
+	settings, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	profile, err := settings.Profile("norebase")
+	if err != nil {
+		t.Fatalf("Profile(norebase) error = %v", err)
+	}
+	want := map[string]string{
+		"branch.autosetupmerge":         "always",
+		"branch.autosetuprebase":        "never",
+		"url.git@github.com:.insteadof": "https://github.com/",
+	}
+	if len(profile) != len(want) {
+		t.Errorf("Profile(norebase) = %v, want %v", profile, want)
+	}
+	for key, value := range want {
+		if profile[key] != value {
+			t.Errorf("Profile(norebase)[%s] = %q, want %q", key, profile[key], value)
+		}
+	}
+
+	// The default profile can be customised too
+	if profile, err := settings.Profile(DefaultProfile); err != nil || profile["pull.rebase"] != "true" || profile["push.default"] != "current" {
+		t.Errorf("Profile(default) = %v, %v", profile, err)
+	}
+
+	if _, err := settings.Profile("missing"); err == nil || !strings.Contains(err.Error(), "default, norebase") {
+		t.Errorf("Profile(missing) error = %v, want it to list the known profiles", err)
+	}
+}
+
+func TestSetAndUnsetInFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.toml")
+
+	if err := SetInFile(path, "gwtm.branch.prefix", []string{"feat/", "fix/"}); err != nil {
+		t.Fatalf("SetInFile() error = %v", err)
+	}
+	if err := SetInFile(path, "hooks", []string{"false"}); err != nil {
+		t.Fatalf("SetInFile() error = %v", err)
+	}
+	if err := SetInFile(path, "protocol", []string{"gopher"}); err == nil {
+		t.Error("SetInFile() with an invalid value succeeded, want an error")
+	}
+
+	data, _ := os.ReadFile(path)
+	if want := "hooks = false\n\n[branch]\nprefix = [\"feat/\", \"fix/\"]\n"; string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+
+	if removed, err := UnsetInFile(path, "branch.prefix"); err != nil || !removed {
+		t.Errorf("UnsetInFile() = %v, %v, want true, nil", removed, err)
+	}
+	if removed, err := UnsetInFile(path, "branch.prefix"); err != nil || removed {
+		t.Errorf("UnsetInFile() of a missing key = %v, %v, want false, nil", removed, err)
+	}
+}
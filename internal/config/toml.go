@@ -0,0 +1,439 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// gwtm's settings files use the subset of TOML that flat settings need:
+// tables, dotted keys, strings, booleans, numbers and single-line arrays of
+// those. Edits rewrite only the affected line, so comments and layout survive
+// 'gwtm config set'.
+
+// tomlEntry is one key/value line of a TOML file
+type tomlEntry struct {
+	Line       int    // Index of the line in the file
+	Table      string // Table the entry is in; empty for top-level entries
+	TableParts int    // Number of key parts in Table
+	KeyText    string // Key as written, relative to the table
+	Key        string // Full dotted key, including the table
+	Values     []string
+}
+
+// tomlDoc is a TOML file split into lines, with the entries and table headers found in it
+type tomlDoc struct {
+	lines   []string
+	entries []tomlEntry
+	tables  map[string]int // Lower-cased table name → line of its header
+}
+
+var (
+	bareKeyRegex   = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	bareValueRegex = regexp.MustCompile(`^(true|false|[+-]?[0-9][0-9_]*(\.[0-9_]+)?([eE][+-]?[0-9_]+)?)$`)
+)
+
+// parseTOML reads a settings file
+func parseTOML(data string) (*tomlDoc, error) {
+	doc := &tomlDoc{tables: map[string]int{}}
+	if data != "" {
+		doc.lines = strings.Split(strings.TrimSuffix(data, "\n"), "\n")
+	}
+
+	table, tableParts := "", 0
+	for i, raw := range doc.lines {
+		line := strings.TrimSpace(strings.TrimSuffix(raw, "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fail := func(err error) (*tomlDoc, error) {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if strings.HasPrefix(line, "[[") {
+				return fail(fmt.Errorf("arrays of tables are not supported"))
+			}
+			end := indexUnquoted(line, ']')
+			if end < 0 {
+				return fail(fmt.Errorf("unterminated table header"))
+			}
+			if rest := strings.TrimSpace(line[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return fail(fmt.Errorf("unexpected %q after table header", rest))
+			}
+			parts, err := parseTOMLKey(line[1:end])
+			if err != nil {
+				return fail(err)
+			}
+			table, tableParts = strings.Join(parts, "."), len(parts)
+			doc.tables[strings.ToLower(table)] = i
+			continue
+		}
+
+		eq := indexUnquoted(line, '=')
+		if eq < 0 {
+			return fail(fmt.Errorf("expected key = value"))
+		}
+		keyText := strings.TrimSpace(line[:eq])
+		parts, err := parseTOMLKey(keyText)
+		if err != nil {
+			return fail(err)
+		}
+		values, rest, err := parseTOMLValue(strings.TrimSpace(line[eq+1:]), true)
+		if err != nil {
+			return fail(err)
+		}
+		if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+			return fail(fmt.Errorf("unexpected %q after value", rest))
+		}
+
+		key := strings.Join(parts, ".")
+		if table != "" {
+			key = table + "." + key
+		}
+		doc.entries = append(doc.entries, tomlEntry{Line: i, Table: table, TableParts: tableParts, KeyText: keyText, Key: key, Values: values})
+	}
+
+	return doc, nil
+}
+
+// indexUnquoted returns the index of the first c in s outside quotes, or -1
+func indexUnquoted(s string, c byte) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == c:
+			return i
+		}
+	}
+	return -1
+}
+
+// parseTOMLKey splits a possibly dotted, possibly quoted key into its parts
+func parseTOMLKey(s string) ([]string, error) {
+	var parts []string
+	for {
+		s = strings.TrimSpace(s)
+		var part string
+		if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'") {
+			values, rest, err := parseTOMLValue(s, false)
+			if err != nil {
+				return nil, err
+			}
+			part, s = values[0], rest
+		} else {
+			end := strings.IndexByte(s, '.')
+			if end < 0 {
+				end = len(s)
+			}
+			part, s = strings.TrimSpace(s[:end]), s[end:]
+			if !bareKeyRegex.MatchString(part) {
+				return nil, fmt.Errorf("invalid key %q", part)
+			}
+		}
+		parts = append(parts, part)
+
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return parts, nil
+		}
+		if s[0] != '.' {
+			return nil, fmt.Errorf("unexpected %q in key", s)
+		}
+		s = s[1:]
+	}
+}
+
+// parseTOMLValue parses the value at the start of s and returns it with the
+// rest of s. Arrays, allowed when array is set, yield one value per element.
+func parseTOMLValue(s string, array bool) (values []string, rest string, err error) {
+	switch {
+	case s == "":
+		return nil, "", fmt.Errorf("missing value")
+
+	case strings.HasPrefix(s, `"""`) || strings.HasPrefix(s, "'''"):
+		return nil, "", fmt.Errorf("multi-line strings are not supported")
+
+	case s[0] == '"':
+		end := 1
+		for ; end < len(s) && s[end] != '"'; end++ {
+			if s[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(s) {
+			return nil, "", fmt.Errorf("unterminated string")
+		}
+		value, err := unescapeTOML(s[1:end])
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid string %s: %w", s[:end+1], err)
+		}
+		return []string{value}, s[end+1:], nil
+
+	case s[0] == '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return nil, "", fmt.Errorf("unterminated string")
+		}
+		return []string{s[1 : end+1]}, s[end+2:], nil
+
+	case s[0] == '[':
+		if !array {
+			return nil, "", fmt.Errorf("nested arrays are not supported")
+		}
+		rest = strings.TrimSpace(s[1:])
+		values = []string{}
+		for !strings.HasPrefix(rest, "]") {
+			if rest == "" || strings.HasPrefix(rest, "#") {
+				return nil, "", fmt.Errorf("multi-line arrays are not supported")
+			}
+			var element []string
+			element, rest, err = parseTOMLValue(rest, false)
+			if err != nil {
+				return nil, "", err
+			}
+			values = append(values, element...)
+			rest = strings.TrimSpace(rest)
+			if strings.HasPrefix(rest, ",") {
+				rest = strings.TrimSpace(rest[1:])
+			} else if !strings.HasPrefix(rest, "]") {
+				return nil, "", fmt.Errorf("expected , or ] in array")
+			}
+		}
+		return values, rest[1:], nil
+
+	case s[0] == '{':
+		return nil, "", fmt.Errorf("inline tables are not supported")
+
+	default:
+		end := strings.IndexAny(s, " \t,]#")
+		if end < 0 {
+			end = len(s)
+		}
+		if !bareValueRegex.MatchString(s[:end]) {
+			return nil, "", fmt.Errorf("invalid value %q (strings need quotes)", s[:end])
+		}
+		return []string{strings.ReplaceAll(s[:end], "_", "")}, s[end:], nil
+	}
+}
+
+// formatTOMLValue writes values as TOML for a setting of kind
+func formatTOMLValue(kind Kind, values []string) string {
+	switch kind {
+	case Bool:
+		value, _ := ParseBool(values[0])
+		return strconv.FormatBool(value)
+	case Int:
+		return values[0]
+	case List:
+		quoted := make([]string, len(values))
+		for i, v := range values {
+			quoted[i] = quoteTOML(v)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	default:
+		return quoteTOML(values[0])
+	}
+}
+
+// quoteTOML writes s as a TOML basic string
+func quoteTOML(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// tomlEscapes maps the letter of each short escape in a basic string to the
+// character it stands for
+var tomlEscapes = map[byte]rune{'b': '\b', 't': '\t', 'n': '\n', 'f': '\f', 'r': '\r', 'e': 0x1b, '"': '"', '\\': '\\'}
+
+// unescapeTOML resolves the escapes in the body of a TOML basic string. They
+// differ from Go's: \e is escape, while \a, \v and octal escapes don't exist.
+func unescapeTOML(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			if (c < 0x20 && c != '\t') || c == 0x7f {
+				return "", fmt.Errorf("control character %U must be escaped", rune(c))
+			}
+			b.WriteByte(c)
+			continue
+		}
+		if i++; i >= len(s) {
+			return "", fmt.Errorf("unfinished escape")
+		}
+		if r, ok := tomlEscapes[s[i]]; ok {
+			b.WriteRune(r)
+			continue
+		}
+
+		digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[s[i]]
+		if digits == 0 {
+			return "", fmt.Errorf("unknown escape \\%c", s[i])
+		}
+		if i+digits >= len(s) {
+			return "", fmt.Errorf("short escape \\%s", s[i:])
+		}
+		code, err := strconv.ParseUint(s[i+1:i+1+digits], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return "", fmt.Errorf("invalid escape \\%s", s[i:i+1+digits])
+		}
+		b.WriteRune(rune(code))
+		i += digits
+	}
+	return b.String(), nil
+}
+
+// formatTOMLKey writes key parts as a dotted TOML key, quoting parts that
+// aren't bare keys
+func formatTOMLKey(parts []string) string {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		if bareKeyRegex.MatchString(part) {
+			quoted[i] = part
+		} else {
+			quoted[i] = quoteTOML(part)
+		}
+	}
+	return strings.Join(quoted, ".")
+}
+
+// find returns the index of the entry for key, or -1
+func (d *tomlDoc) find(key string) int {
+	return slices.IndexFunc(d.entries, func(e tomlEntry) bool {
+		return CanonicalKey(e.Key) == CanonicalKey(key)
+	})
+}
+
+// set gives key the already formatted value: in place when the file has the
+// key, otherwise next to the entries of its table, creating the table when
+// needed
+func (d *tomlDoc) set(key, value string) {
+	if i := d.find(key); i >= 0 {
+		e := d.entries[i]
+		d.lines[e.Line] = indentOf(d.lines[e.Line]) + e.KeyText + " = " + value
+		return
+	}
+
+	// The key goes next to the entries of the deepest table the file already
+	// has. A table made by dotted keys can't be given a header as well, so
+	// then the key joins the last of those entries, as a dotted key too.
+	parts := keyParts(key)
+	for n := len(parts) - 1; n > 0; n-- {
+		table := strings.Join(parts[:n], ".")
+		if header, ok := d.tables[strings.ToLower(table)]; ok {
+			at := header
+			for _, e := range d.entries {
+				if e.TableParts == n && strings.EqualFold(e.Table, table) {
+					at = max(at, e.Line)
+				}
+			}
+			d.insert(at, formatTOMLKey(parts[n:])+" = "+value)
+			return
+		}
+
+		for i := len(d.entries) - 1; i >= 0; i-- {
+			e := d.entries[i]
+			if e.TableParts < n && strings.EqualFold(e.Table, strings.Join(parts[:e.TableParts], ".")) &&
+				strings.HasPrefix(strings.ToLower(e.Key), strings.ToLower(table)+".") {
+				d.insert(e.Line, indentOf(d.lines[e.Line])+formatTOMLKey(parts[e.TableParts:])+" = "+value)
+				return
+			}
+		}
+	}
+
+	if len(parts) == 1 {
+		// Top-level entries go after the last one, or before the first table
+		at := -1
+		for _, e := range d.entries {
+			if e.Table == "" {
+				at = max(at, e.Line)
+			}
+		}
+		if at < 0 {
+			at = len(d.lines) - 1
+			for _, line := range d.tables {
+				at = min(at, line-1)
+			}
+			for at >= 0 && strings.TrimSpace(d.lines[at]) == "" {
+				at--
+			}
+		}
+		d.insert(at, formatTOMLKey(parts)+" = "+value)
+		return
+	}
+
+	if n := len(d.lines); n > 0 && strings.TrimSpace(d.lines[n-1]) != "" {
+		d.lines = append(d.lines, "")
+	}
+	d.lines = append(d.lines, "["+formatTOMLKey(parts[:len(parts)-1])+"]", formatTOMLKey(parts[len(parts)-1:])+" = "+value)
+}
+
+// insert adds line after the line at index at, keeping a blank line between
+// it and a table header that follows
+func (d *tomlDoc) insert(at int, line string) {
+	d.lines = slices.Insert(d.lines, at+1, line)
+	if next := at + 2; next < len(d.lines) && strings.HasPrefix(strings.TrimSpace(d.lines[next]), "[") {
+		d.lines = slices.Insert(d.lines, next, "")
+	}
+}
+
+// indentOf returns the whitespace line starts with
+func indentOf(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// unset removes key and reports whether the file had it
+func (d *tomlDoc) unset(key string) bool {
+	i := d.find(key)
+	if i < 0 {
+		return false
+	}
+	d.lines = slices.Delete(d.lines, d.entries[i].Line, d.entries[i].Line+1)
+	return true
+}
+
+// String returns the file's contents
+func (d *tomlDoc) String() string {
+	if len(d.lines) == 0 {
+		return ""
+	}
+	return strings.Join(d.lines, "\n") + "\n"
+}
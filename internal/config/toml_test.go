@@ -0,0 +1,233 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	content := `# gwtm settings
+protocol = "https"   # over SSH
+branch.maxLength = 40
+submodules = true
+
+[branch]
+template = '{type}/{slug}'
+prefix = ["feat/", "fix/",]
+
+[host]
+gl = "gitlab.example.com"
+"my-host" = "git.example.com:8443"
+`
+	doc, err := parseTOML(content)
+	if err != nil {
+		t.Fatalf("parseTOML() error = %v", err)
+	}
+
+	var got []string
+	for _, e := range doc.entries {
+		got = append(got, e.Key+"="+strings.Join(e.Values, ","))
+	}
+	want := []string{
+		"protocol=https",
+		"branch.maxLength=40",
+		"submodules=true",
+		"branch.template={type}/{slug}",
+		"branch.prefix=feat/,fix/",
+		"host.gl=gitlab.example.com",
+		"host.my-host=git.example.com:8443",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("parseTOML() entries =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestParseTOML_Errors(t *testing.T) {
+	tests := []struct {
+		content string
+		wantErr string
+	}{
+		{"protocol = https", "strings need quotes"},
+		{`protocol = "https`, "unterminated string"},
+		{"protocol", "expected key = value"},
+		{"[branch", "unterminated table header"},
+		{"[[repo]]", "arrays of tables"},
+		{`prefix = ["feat/",`, "multi-line arrays"},
+		{"host = { gl = 'x' }", "inline tables"},
+		{`template = """x"""`, "multi-line strings"},
+		{`protocol = "ssh" extra`, "after value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			_, err := parseTOML("# ok\n" + tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.HasPrefix(err.Error(), "line 2:") {
+				t.Errorf("parseTOML(%q) error = %v, want line 2: ...%s...", tt.content, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTOMLDocSetUnset(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		edit  func(d *tomlDoc)
+		want  string
+	}{
+		{
+			name:  "replace keeps the key's spelling and indent",
+			input: "# mine\n[branch]\n  maxlength = 20 # old\n",
+			edit:  func(d *tomlDoc) { d.set("branch.maxLength", "40") },
+			want:  "# mine\n[branch]\n  maxlength = 40\n",
+		},
+		{
+			name:  "new key in an existing table",
+			input: "[branch]\ntemplate = \"x\"\n\n[host]\ngl = \"gitlab.com\"\n",
+			edit:  func(d *tomlDoc) { d.set("branch.pattern", `"feat/.*"`) },
+			want:  "[branch]\ntemplate = \"x\"\npattern = \"feat/.*\"\n\n[host]\ngl = \"gitlab.com\"\n",
+		},
+		{
+			name:  "top-level key goes before the first table",
+			input: "# settings\n\n[host]\ngl = \"gitlab.com\"\n",
+			edit:  func(d *tomlDoc) { d.set("protocol", `"https"`) },
+			want:  "# settings\nprotocol = \"https\"\n\n[host]\ngl = \"gitlab.com\"\n",
+		},
+		{
+			name:  "new table is appended",
+			input: "protocol = \"https\"\n",
+			edit:  func(d *tomlDoc) { d.set("host.gl", `"gitlab.com"`) },
+			want:  "protocol = \"https\"\n\n[host]\ngl = \"gitlab.com\"\n",
+		},
+		{
+			name:  "family members that aren't bare keys are quoted",
+			input: "[host]\ngl = \"gitlab.com\"\n",
+			edit: func(d *tomlDoc) {
+				d.set("host.https://git.example.com/", `"ex"`)
+				d.set("alias.my alias", `"list"`)
+			},
+			want: "[host]\ngl = \"gitlab.com\"\n\"https://git.example.com/\" = \"ex\"\n\n[alias]\n\"my alias\" = \"list\"\n",
+		},
+		{
+			name:  "table made by dotted keys gets another dotted key",
+			input: "profiles.work.pull.rebase = \"true\"\n\n[branch]\ntemplate = \"x\"\n",
+			edit:  func(d *tomlDoc) { d.set("profiles.work.push.default", `"current"`) },
+			want:  "profiles.work.pull.rebase = \"true\"\nprofiles.work.push.default = \"current\"\n\n[branch]\ntemplate = \"x\"\n",
+		},
+		{
+			name:  "dotted keys inside a table",
+			input: "[profiles]\nwork.pull.rebase = \"true\"\n",
+			edit:  func(d *tomlDoc) { d.set("profiles.work.url.git@example.com:.insteadOf", `"https://example.com/"`) },
+			want:  "[profiles]\nwork.pull.rebase = \"true\"\nwork.url.\"git@example.com:\".insteadOf = \"https://example.com/\"\n",
+		},
+		{
+			name:  "empty file",
+			input: "",
+			edit:  func(d *tomlDoc) { d.set("hooks", "false") },
+			want:  "hooks = false\n",
+		},
+		{
+			name:  "unset removes only the key",
+			input: "protocol = \"https\"\n[branch]\nmaxLength = 40\n",
+			edit:  func(d *tomlDoc) { d.unset("branch.maxlength") },
+			want:  "protocol = \"https\"\n[branch]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseTOML(tt.input)
+			if err != nil {
+				t.Fatalf("parseTOML() error = %v", err)
+			}
+			tt.edit(doc)
+			if got := doc.String(); got != tt.want {
+				t.Errorf("after edit =\n%q\nwant\n%q", got, tt.want)
+			}
+			if _, err := parseTOML(doc.String()); err != nil {
+				t.Errorf("edited file does not parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestTOMLDocSet_RoundTrip(t *testing.T) {
+	// Keys and values survive a set and a fresh parse unchanged
+	tests := []struct{ key, value string }{
+		{"host.https://git.example.com/", "git.example.com"},
+		{"host.my host", "with \"quotes\" and \\ backslash"},
+		{"alias.sh", "tab\tnewline\nescape\x1b del\x7f"},
+		{"profiles.work.url.git@example.com:.insteadof", "https://example.com/ 😀"},
+	}
+
+	doc, _ := parseTOML("")
+	for _, tt := range tests {
+		doc.set(tt.key, formatTOMLValue(String, []string{tt.value}))
+	}
+	parsed, err := parseTOML(doc.String())
+	if err != nil {
+		t.Fatalf("parseTOML() of\n%s\nerror = %v", doc, err)
+	}
+	for _, tt := range tests {
+		i := parsed.find(tt.key)
+		if i < 0 {
+			t.Errorf("%s missing after round trip of\n%s", tt.key, doc)
+			continue
+		}
+		if got := parsed.entries[i].Values[0]; got != tt.value {
+			t.Errorf("%s = %q after round trip, want %q", tt.key, got, tt.value)
+		}
+	}
+}
+
+func TestParseTOML_Strings(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: `"esc\e[0m"`, want: "esc\x1b[0m"},
+		{value: `"smile \U0001F600 \u00e9 \x41"`, want: "smile 😀 é A"},
+		{value: `'C:\path\no\escapes'`, want: `C:\path\no\escapes`},
+		{value: `'"quoted"'`, want: `"quoted"`},
+		{value: `"\a"`, wantErr: true},
+		{value: `"\101"`, wantErr: true},
+		{value: `"\uD800"`, wantErr: true},
+		{value: `"\U0011FFFF"`, wantErr: true},
+		{value: `"\u12"`, wantErr: true},
+		{value: "\"raw\x01control\"", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			doc, err := parseTOML("alias.x = " + tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTOML(%s) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if err == nil && doc.entries[0].Values[0] != tt.want {
+				t.Errorf("parseTOML(%s) = %q, want %q", tt.value, doc.entries[0].Values[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTOMLValue(t *testing.T) {
+	tests := []struct {
+		kind   Kind
+		values []string
+		want   string
+	}{
+		{String, []string{`say "hi"`}, `"say \"hi\""`},
+		{Bool, []string{"true"}, "true"},
+		{Bool, []string{"off"}, "false"},
+		{Int, []string{"40"}, "40"},
+		{List, []string{"feat/", "fix/"}, `["feat/", "fix/"]`},
+		{String, []string{"a\tb\x1b\x7f"}, `"a\tb\u001B\u007F"`},
+	}
+
+	for _, tt := range tests {
+		if got := formatTOMLValue(tt.kind, tt.values); got != tt.want {
+			t.Errorf("formatTOMLValue(%v, %v) = %s, want %s", tt.kind, tt.values, got, tt.want)
+		}
+	}
+}
@@ -22,6 +22,78 @@ func (c *Client) BranchExists(name string, remote bool) bool {
 	return strings.TrimSpace(stdout) != ""
 }
 
+// RefExists checks if a fully-qualified ref (e.g. refs/remotes/origin/HEAD) resolves
+func (c *Client) RefExists(ref string) bool {
+	_, _, err := c.ExecGit("rev-parse", "--verify", "--quiet", ref)
+	return err == nil
+}
+
+// ResolveCommit returns the full hash of the commit that rev (a branch, tag
+// or abbreviated hash) refers to
+func (c *Client) ResolveCommit(rev string) (string, error) {
+	// Read-only query — run it even in dry-run mode
+	reader := &Client{WorkDir: c.WorkDir}
+	stdout, _, err := reader.ExecGit("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%q is not a known tag, branch or commit", rev)
+	}
+
+	return strings.TrimSpace(stdout), nil
+}
+
+// CheckBranchName reports whether name is a valid branch name according to
+// git's ref naming rules
+func (c *Client) CheckBranchName(name string) error {
+	// Read-only query — run it even in dry-run mode
+	reader := &Client{WorkDir: c.WorkDir}
+	if _, _, err := reader.ExecGit("check-ref-format", "--branch", name); err != nil {
+		return fmt.Errorf("%q is not a valid branch name", name)
+	}
+
+	return nil
+}
+
+// Upstream describes the branch a local branch tracks
+type Upstream struct {
+	Name   string // Short name, e.g. origin/main; "" when the branch has no upstream
+	Gone   bool   // The upstream is configured but no longer exists
+	Ahead  int    // Commits on the branch that are not on the upstream
+	Behind int    // Commits on the upstream that are not on the branch
+}
+
+// BranchUpstream returns the upstream of the local branch name and how far
+// the two have diverged, as of the last fetch
+func (c *Client) BranchUpstream(name string) (Upstream, error) {
+	// Read-only query — run it even in dry-run mode
+	reader := &Client{WorkDir: c.WorkDir}
+	stdout, _, err := reader.ExecGit("for-each-ref", "--format=%(upstream:short)%00%(upstream:track,nobracket)", "refs/heads/"+name)
+	if err != nil {
+		return Upstream{}, fmt.Errorf("failed to read upstream of %s: %w", name, err)
+	}
+
+	upstreamName, track, _ := strings.Cut(strings.TrimSpace(stdout), "\x00")
+	return parseUpstreamTrack(upstreamName, track), nil
+}
+
+// parseUpstreamTrack interprets %(upstream:track,nobracket) output such as
+// "ahead 2, behind 1" or "gone"
+func parseUpstreamTrack(name, track string) Upstream {
+	upstream := Upstream{Name: name}
+	if track == "gone" {
+		upstream.Gone = true
+		return upstream
+	}
+	for _, part := range strings.Split(track, ", ") {
+		var n int
+		if _, err := fmt.Sscanf(part, "ahead %d", &n); err == nil {
+			upstream.Ahead = n
+		} else if _, err := fmt.Sscanf(part, "behind %d", &n); err == nil {
+			upstream.Behind = n
+		}
+	}
+	return upstream
+}
+
 // CreateBranch creates a new branch from the specified base branch
 func (c *Client) CreateBranch(name, baseBranch string) error {
 	args := []string{"branch", name}
@@ -37,6 +109,47 @@ func (c *Client) CreateBranch(name, baseBranch string) error {
 	return nil
 }
 
+// MergeFastForward fast-forwards the checked-out branch to ref, failing
+// rather than creating a merge commit when the histories have diverged
+func (c *Client) MergeFastForward(ref string) error {
+	_, _, err := c.ExecGit("merge", "--ff-only", ref)
+	if err != nil {
+		return fmt.Errorf("failed to fast-forward to %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+// Rebase replays the checked-out branch's own commits on top of onto
+func (c *Client) Rebase(onto string) error {
+	_, _, err := c.ExecGit("rebase", onto)
+	if err != nil {
+		return fmt.Errorf("failed to rebase onto %s: %w", onto, err)
+	}
+
+	return nil
+}
+
+// RebaseAbort abandons a stopped rebase and restores the branch as it was
+func (c *Client) RebaseAbort() error {
+	_, _, err := c.ExecGit("rebase", "--abort")
+	if err != nil {
+		return fmt.Errorf("failed to abort rebase: %w", err)
+	}
+
+	return nil
+}
+
+// RenameBranch renames a local branch, including when it is checked out in a worktree
+func (c *Client) RenameBranch(oldName, newName string) error {
+	_, _, err := c.ExecGit("branch", "-m", oldName, newName)
+	if err != nil {
+		return fmt.Errorf("failed to rename branch: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteBranch deletes a local branch
 func (c *Client) DeleteBranch(name string, force bool) error {
 	flag := "-d"
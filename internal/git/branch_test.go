@@ -181,3 +181,189 @@ func TestDeleteRemoteBranch(t *testing.T) {
 		})
 	}
 }
+
+func TestRefExists(t *testing.T) {
+	client, _, defaultBranch := setupBranchTestRepo(t)
+
+	if !client.RefExists("refs/heads/" + defaultBranch) {
+		t.Errorf("RefExists(refs/heads/%s) = false, want true", defaultBranch)
+	}
+	if client.RefExists("refs/remotes/origin/HEAD") {
+		t.Error("RefExists(refs/remotes/origin/HEAD) = true in a repo without remotes, want false")
+	}
+}
+
+func TestMergeFastForward(t *testing.T) {
+	client, tmpDir, defaultBranch := setupBranchTestRepo(t)
+
+	// ahead is one commit ahead of the default branch
+	client.ExecGit("checkout", "-b", "ahead")
+	os.WriteFile(filepath.Join(tmpDir, "ahead.txt"), []byte("ahead\n"), 0644)
+	client.ExecGit("add", "ahead.txt")
+	client.ExecGit("commit", "-m", "Ahead")
+
+	// diverged has its own commit on top of the default branch
+	client.ExecGit("checkout", "-b", "diverged", defaultBranch)
+	os.WriteFile(filepath.Join(tmpDir, "diverged.txt"), []byte("diverged\n"), 0644)
+	client.ExecGit("add", "diverged.txt")
+	client.ExecGit("commit", "-m", "Diverged")
+
+	if err := client.MergeFastForward("ahead"); err == nil {
+		t.Error("MergeFastForward() on diverged history error = nil, want error")
+	}
+
+	client.ExecGit("checkout", defaultBranch)
+	if err := client.MergeFastForward("ahead"); err != nil {
+		t.Fatalf("MergeFastForward() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "ahead.txt")); err != nil {
+		t.Error("MergeFastForward() did not bring in the new commit")
+	}
+}
+
+func TestResolveCommit(t *testing.T) {
+	client, _, defaultBranch := setupBranchTestRepo(t)
+	client.ExecGit("tag", "-a", "v1.0.0", "-m", "Release")
+
+	head, _, _ := client.ExecGit("rev-parse", "HEAD")
+	head = strings.TrimSpace(head)
+
+	for _, rev := range []string{defaultBranch, "v1.0.0", head[:8]} {
+		got, err := client.ResolveCommit(rev)
+		if err != nil {
+			t.Errorf("ResolveCommit(%q) error = %v", rev, err)
+			continue
+		}
+		if got != head {
+			t.Errorf("ResolveCommit(%q) = %s, want %s", rev, got, head)
+		}
+	}
+
+	if _, err := client.ResolveCommit("no-such-rev"); err == nil {
+		t.Error("ResolveCommit() for unknown rev error = nil, want error")
+	}
+}
+
+func TestCheckBranchName(t *testing.T) {
+	client := NewClient(t.TempDir())
+
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "feature-login", wantErr: false},
+		{name: "feat/ABC-12-add-login", wantErr: false},
+		{name: "has space", wantErr: true},
+		{name: "double..dot", wantErr: true},
+		{name: "ends-with.lock", wantErr: true},
+		{name: "-leading-dash", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.CheckBranchName(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckBranchName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenameBranch(t *testing.T) {
+	client, _, _ := setupBranchTestRepo(t)
+	client.ExecGit("branch", "old-name")
+
+	if err := client.RenameBranch("old-name", "new-name"); err != nil {
+		t.Fatalf("RenameBranch() error = %v", err)
+	}
+	if client.BranchExists("old-name", false) || !client.BranchExists("new-name", false) {
+		t.Error("RenameBranch() did not rename the branch")
+	}
+
+	if err := client.RenameBranch("missing", "other"); err == nil {
+		t.Error("RenameBranch() for missing branch error = nil, want error")
+	}
+}
+
+func TestParseUpstreamTrack(t *testing.T) {
+	tests := []struct {
+		track string
+		want  Upstream
+	}{
+		{"", Upstream{Name: "origin/main"}},
+		{"ahead 2", Upstream{Name: "origin/main", Ahead: 2}},
+		{"behind 3", Upstream{Name: "origin/main", Behind: 3}},
+		{"ahead 1, behind 4", Upstream{Name: "origin/main", Ahead: 1, Behind: 4}},
+		{"gone", Upstream{Name: "origin/main", Gone: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.track, func(t *testing.T) {
+			if got := parseUpstreamTrack("origin/main", tt.track); got != tt.want {
+				t.Errorf("parseUpstreamTrack(%q) = %+v, want %+v", tt.track, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBranchUpstream(t *testing.T) {
+	client, tmpDir, defaultBranch := setupBranchTestRepo(t)
+
+	// behind tracks the default branch, which then gets a new commit
+	client.ExecGit("branch", "--track", "behind", defaultBranch)
+	os.WriteFile(filepath.Join(tmpDir, "new.txt"), []byte("new\n"), 0644)
+	client.ExecGit("add", "new.txt")
+	client.ExecGit("commit", "-m", "New")
+
+	got, err := client.BranchUpstream("behind")
+	if err != nil {
+		t.Fatalf("BranchUpstream() error = %v", err)
+	}
+	if want := (Upstream{Name: defaultBranch, Behind: 1}); got != want {
+		t.Errorf("BranchUpstream(behind) = %+v, want %+v", got, want)
+	}
+
+	got, err = client.BranchUpstream(defaultBranch)
+	if err != nil {
+		t.Fatalf("BranchUpstream() error = %v", err)
+	}
+	if got.Name != "" {
+		t.Errorf("BranchUpstream(%s) = %+v, want no upstream", defaultBranch, got)
+	}
+}
+
+func TestRebase(t *testing.T) {
+	client, tmpDir, defaultBranch := setupBranchTestRepo(t)
+	commit := func(content, message string) {
+		os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte(content), 0644)
+		client.ExecGit("add", "file.txt")
+		client.ExecGit("commit", "-m", message)
+	}
+
+	client.ExecGit("checkout", "-b", "topic")
+	commit("topic\n", "Topic")
+	client.ExecGit("checkout", defaultBranch)
+	commit("main\n", "Main")
+	client.ExecGit("checkout", "topic")
+
+	if err := client.Rebase(defaultBranch); err == nil {
+		t.Fatal("Rebase() with conflicting changes error = nil, want error")
+	}
+	if err := client.RebaseAbort(); err != nil {
+		t.Fatalf("RebaseAbort() error = %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(tmpDir, "file.txt")); string(content) != "topic\n" {
+		t.Errorf("after RebaseAbort() file.txt = %q, want the branch's own content", content)
+	}
+
+	client.ExecGit("checkout", "-b", "clean", defaultBranch+"~1")
+	os.WriteFile(filepath.Join(tmpDir, "other.txt"), []byte("other\n"), 0644)
+	client.ExecGit("add", "other.txt")
+	client.ExecGit("commit", "-m", "Other")
+	if err := client.Rebase(defaultBranch); err != nil {
+		t.Fatalf("Rebase() error = %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(tmpDir, "file.txt")); string(content) != "main\n" {
+		t.Errorf("after Rebase() file.txt = %q, want the upstream's content", content)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -54,3 +56,41 @@ func (c *Client) ExecGit(args ...string) (stdout, stderr string, err error) {
 
 	return stdout, stderr, err
 }
+
+var gitVersionRegex = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// Version returns the installed git version. It always runs git, even in
+// dry-run mode, since it has no side effects.
+func (c *Client) Version() (major, minor, patch int, err error) {
+	reader := &Client{WorkDir: c.WorkDir}
+	stdout, _, err := reader.ExecGit("version")
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to get git version: %w", err)
+	}
+
+	return parseGitVersion(stdout)
+}
+
+// parseGitVersion extracts the version from output such as
+// "git version 2.39.5" or "git version 2.39.3 (Apple Git-146)"
+func parseGitVersion(output string) (major, minor, patch int, err error) {
+	matches := gitVersionRegex.FindStringSubmatch(output)
+	if matches == nil {
+		return 0, 0, 0, fmt.Errorf("unrecognised git version output %q", strings.TrimSpace(output))
+	}
+
+	major, _ = strconv.Atoi(matches[1])
+	minor, _ = strconv.Atoi(matches[2])
+	patch, _ = strconv.Atoi(matches[3]) // empty when git reports only major.minor
+
+	return major, minor, patch, nil
+}
+
+// AtLeastVersion reports whether the installed git is at least major.minor
+func (c *Client) AtLeastVersion(major, minor int) bool {
+	gotMajor, gotMinor, _, err := c.Version()
+	if err != nil {
+		return false
+	}
+	return gotMajor > major || (gotMajor == major && gotMinor >= minor)
+}
@@ -122,3 +122,60 @@ func TestDryRunMode(t *testing.T) {
 		// This is acceptable - dry run might not produce output
 	}
 }
+
+func TestParseGitVersion(t *testing.T) {
+	tests := []struct {
+		name                            string
+		output                          string
+		wantMajor, wantMinor, wantPatch int
+		wantErr                         bool
+	}{
+		{
+			name:      "linux",
+			output:    "git version 2.39.5\n",
+			wantMajor: 2, wantMinor: 39, wantPatch: 5,
+		},
+		{
+			name:      "apple git",
+			output:    "git version 2.39.3 (Apple Git-146)\n",
+			wantMajor: 2, wantMinor: 39, wantPatch: 3,
+		},
+		{
+			name:      "windows",
+			output:    "git version 2.48.1.windows.1\n",
+			wantMajor: 2, wantMinor: 48, wantPatch: 1,
+		},
+		{
+			name:    "unrecognised",
+			output:  "not git",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			major, minor, patch, err := parseGitVersion(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGitVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if major != tt.wantMajor || minor != tt.wantMinor || patch != tt.wantPatch {
+				t.Errorf("parseGitVersion() = %d.%d.%d, want %d.%d.%d", major, minor, patch, tt.wantMajor, tt.wantMinor, tt.wantPatch)
+			}
+		})
+	}
+}
+
+func TestAtLeastVersion(t *testing.T) {
+	client := NewClient("")
+	client.DryRun = true // Version must still query git in dry-run mode
+
+	if !client.AtLeastVersion(2, 5) {
+		t.Error("AtLeastVersion(2, 5) = false, want true (worktree support is required)")
+	}
+	if client.AtLeastVersion(99, 0) {
+		t.Error("AtLeastVersion(99, 0) = true, want false")
+	}
+}
@@ -1,7 +1,11 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
 )
 
 // SetConfig sets a git configuration value
@@ -14,10 +18,142 @@ func (c *Client) SetConfig(key, value string) error {
 	return nil
 }
 
+// GetConfig returns the value of a git configuration key.
+// An unset key is not an error — it returns an empty string.
+func (c *Client) GetConfig(key string) (string, error) {
+	stdout, _, err := c.ExecGit("config", "--get", key)
+	if err != nil {
+		if isConfigNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get config %s: %w", key, err)
+	}
+
+	return strings.TrimSpace(stdout), nil
+}
+
+// GetConfigAll returns every value of a multi-valued git configuration key.
+// An unset key is not an error — it returns an empty slice.
+func (c *Client) GetConfigAll(key string) ([]string, error) {
+	stdout, _, err := c.ExecGit("config", "--get-all", key)
+	if err != nil {
+		if isConfigNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get config %s: %w", key, err)
+	}
+
+	var values []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if line != "" {
+			values = append(values, line)
+		}
+	}
+
+	return values, nil
+}
+
+// GetConfigRegexp returns all configuration keys matching the given regular
+// expression, mapped to their values. Later values win for multi-valued keys.
+func (c *Client) GetConfigRegexp(pattern string) (map[string]string, error) {
+	stdout, _, err := c.ExecGit("config", "--get-regexp", pattern)
+	if err != nil {
+		if isConfigNotFound(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config matching %s: %w", pattern, err)
+	}
+
+	values := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if line == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+// ConfigEntry is a single key/value pair read from a git config file
+type ConfigEntry struct {
+	Key   string
+	Value string
+}
+
+// ListConfigFile returns every entry in a git-config-syntax file, in file order.
+// Multi-valued keys appear once per value.
+func (c *Client) ListConfigFile(path string) ([]ConfigEntry, error) {
+	stdout, _, err := c.ExecGit("config", "--file", path, "--list", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var entries []ConfigEntry
+	for _, record := range strings.Split(stdout, "\x00") {
+		if record == "" {
+			continue
+		}
+		// With -z, each key is separated from its value by a newline
+		key, value, _ := strings.Cut(record, "\n")
+		entries = append(entries, ConfigEntry{Key: key, Value: value})
+	}
+
+	return entries, nil
+}
+
+// ListConfigScope returns the entries matching pattern in one scope of git
+// config — "system", "global" or "local" — in file order. Multi-valued keys
+// appear once per value. Keys come back the way git reports them: section and
+// variable names in lower case.
+func (c *Client) ListConfigScope(scope, pattern string) ([]ConfigEntry, error) {
+	// Read-only query — run it even in dry-run mode
+	reader := &Client{WorkDir: c.WorkDir}
+	stdout, _, err := reader.ExecGit("config", "--"+scope, "-z", "--get-regexp", pattern)
+	if err != nil {
+		if isConfigNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s config matching %s: %w", scope, pattern, err)
+	}
+
+	var entries []ConfigEntry
+	for _, record := range strings.Split(stdout, "\x00") {
+		if record == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(record, "\n")
+		entries = append(entries, ConfigEntry{Key: key, Value: value})
+	}
+
+	return entries, nil
+}
+
+// isConfigNotFound reports whether err is git config's exit status 1,
+// which it uses to signal that the requested key is not set.
+func isConfigNotFound(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == 1
+}
+
+// FetchRefspec is the refspec gwtm configures so that every remote branch is fetched
+const FetchRefspec = "+refs/heads/*:refs/remotes/origin/*"
+
+// DefaultWorktreeSettings returns the git settings gwtm applies to projects
+// for worktree management, unless a profile says otherwise
+func DefaultWorktreeSettings() map[string]string {
+	return map[string]string{
+		"push.default":           "current",
+		"branch.autosetupmerge":  "always",
+		"branch.autosetuprebase": "always",
+	}
+}
+
 // ConfigureFetchRefspec configures the fetch refspec to fetch all remote branches
 func (c *Client) ConfigureFetchRefspec() error {
 	// Set remote.origin.fetch to fetch all branches
-	err := c.SetConfig("remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*")
+	err := c.SetConfig("remote.origin.fetch", FetchRefspec)
 	if err != nil {
 		return fmt.Errorf("failed to configure fetch refspec: %w", err)
 	}
@@ -25,19 +161,47 @@ func (c *Client) ConfigureFetchRefspec() error {
 	return nil
 }
 
-// ConfigureWorktreeSettings configures git settings for worktree management
-func (c *Client) ConfigureWorktreeSettings() error {
-	settings := map[string]string{
-		"push.default":           "current",
-		"branch.autosetupmerge":  "always",
-		"branch.autosetuprebase": "always",
+// ConfigChange is a repository setting that differs from the wanted value
+type ConfigChange struct {
+	Key string
+	Old string // Empty when the repository does not set the key
+	New string
+}
+
+// ConfigChanges compares the repository's own config with settings and
+// returns the keys that differ, sorted by key
+func (c *Client) ConfigChanges(settings map[string]string) ([]ConfigChange, error) {
+	// Read-only query — run it even in dry-run mode
+	reader := &Client{WorkDir: c.WorkDir}
+
+	var changes []ConfigChange
+	for key, want := range settings {
+		stdout, _, err := reader.ExecGit("config", "--local", "--get", key)
+		if err != nil && !isConfigNotFound(err) {
+			return nil, fmt.Errorf("failed to get config %s: %w", key, err)
+		}
+		if got := strings.TrimSpace(stdout); got != want {
+			changes = append(changes, ConfigChange{Key: key, Old: got, New: want})
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b ConfigChange) int { return strings.Compare(a.Key, b.Key) })
+	return changes, nil
+}
+
+// ApplyConfig sets the repository's own config to settings and returns what
+// changed. In dry-run mode nothing is written, but the changes are still returned.
+func (c *Client) ApplyConfig(settings map[string]string) ([]ConfigChange, error) {
+	changes, err := c.ConfigChanges(settings)
+	if err != nil {
+		return nil, err
 	}
 
-	for key, value := range settings {
-		if err := c.SetConfig(key, value); err != nil {
-			return err
+	for _, change := range changes {
+		if err := c.SetConfig(change.Key, change.New); err != nil {
+			return nil, err
 		}
 	}
 
-	return nil
+	return changes, nil
 }
@@ -116,27 +116,206 @@ func TestConfigureFetchRefspec(t *testing.T) {
 	}
 }
 
-func TestConfigureWorktreeSettings(t *testing.T) {
+func TestApplyConfig(t *testing.T) {
 	client, _ := setupConfigTestRepo(t)
+	client.ExecGit("config", "push.default", "simple")
 
-	// This is a convenience function that should set multiple config values
-	err := client.ConfigureWorktreeSettings()
+	changes, err := client.ApplyConfig(DefaultWorktreeSettings())
 	if err != nil {
-		t.Fatalf("ConfigureWorktreeSettings() error = %v", err)
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	want := []ConfigChange{
+		{Key: "branch.autosetupmerge", Old: "", New: "always"},
+		{Key: "branch.autosetuprebase", Old: "", New: "always"},
+		{Key: "push.default", Old: "simple", New: "current"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("ApplyConfig() = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("ApplyConfig()[%d] = %v, want %v", i, changes[i], want[i])
+		}
 	}
 
 	// Verify all required settings were configured
-	requiredConfigs := map[string]string{
-		"push.default":              "current",
-		"branch.autosetupmerge":     "always",
-		"branch.autosetuprebase":    "always",
+	for key, expectedValue := range DefaultWorktreeSettings() {
+		stdout, _, _ := client.ExecGit("config", "--get", key)
+		if got := strings.TrimSpace(stdout); got != expectedValue {
+			t.Errorf("ApplyConfig() %s = %s, want %s", key, got, expectedValue)
+		}
 	}
 
-	for key, expectedValue := range requiredConfigs {
-		stdout, _, _ := client.ExecGit("config", "--get", key)
-		got := strings.TrimSpace(stdout)
-		if got != expectedValue {
-			t.Errorf("ConfigureWorktreeSettings() %s = %s, want %s", key, got, expectedValue)
+	// Applying again changes nothing
+	if changes, err := client.ApplyConfig(DefaultWorktreeSettings()); err != nil || len(changes) != 0 {
+		t.Errorf("second ApplyConfig() = %v, %v, want no changes", changes, err)
+	}
+
+	// Dry runs report changes without making them
+	client.DryRun = true
+	changes, err = client.ApplyConfig(map[string]string{"push.default": "upstream"})
+	if err != nil || len(changes) != 1 {
+		t.Errorf("dry-run ApplyConfig() = %v, %v, want one change", changes, err)
+	}
+	if stdout, _, _ := NewClient(client.WorkDir).ExecGit("config", "--get", "push.default"); strings.TrimSpace(stdout) != "current" {
+		t.Errorf("dry-run ApplyConfig() changed push.default to %s", stdout)
+	}
+}
+
+func TestGetConfig(t *testing.T) {
+	client, _ := setupConfigTestRepo(t)
+	client.SetConfig("gwtm.defaultHost", "gitlab.example.com")
+
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{
+			name: "set key",
+			key:  "gwtm.defaultHost",
+			want: "gitlab.example.com",
+		},
+		{
+			name: "unset key returns empty string",
+			key:  "gwtm.doesNotExist",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.GetConfig(tt.key)
+			if err != nil {
+				t.Fatalf("GetConfig() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetConfig() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetConfigRegexp(t *testing.T) {
+	client, _ := setupConfigTestRepo(t)
+	client.SetConfig("gwtm.host.gl", "gitlab.example.com")
+	client.SetConfig("gwtm.host.work", "ssh://git@git.work.example:2222/")
+	client.SetConfig("gwtm.protocol", "https")
+
+	got, err := client.GetConfigRegexp(`^gwtm\.host\.`)
+	if err != nil {
+		t.Fatalf("GetConfigRegexp() error = %v", err)
+	}
+
+	want := map[string]string{
+		"gwtm.host.gl":   "gitlab.example.com",
+		"gwtm.host.work": "ssh://git@git.work.example:2222/",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("GetConfigRegexp() = %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("GetConfigRegexp()[%q] = %q, want %q", key, got[key], value)
 		}
 	}
+
+	none, err := client.GetConfigRegexp(`^gwtm\.nothing\.`)
+	if err != nil {
+		t.Fatalf("GetConfigRegexp() with no matches error = %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("GetConfigRegexp() with no matches = %v, want empty", none)
+	}
+}
+
+func TestListConfigFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "manifest")
+	content := `[repo "webapp"]
+	url = acme/webapp
+	config = pull.rebase=false
+	config = core.autocrlf=input
+[repo "api.v2"]
+	url = acme/api
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	got, err := NewClient(tmpDir).ListConfigFile(path)
+	if err != nil {
+		t.Fatalf("ListConfigFile() error = %v", err)
+	}
+
+	want := []ConfigEntry{
+		{Key: "repo.webapp.url", Value: "acme/webapp"},
+		{Key: "repo.webapp.config", Value: "pull.rebase=false"},
+		{Key: "repo.webapp.config", Value: "core.autocrlf=input"},
+		{Key: "repo.api.v2.url", Value: "acme/api"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ListConfigFile() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ListConfigFile()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if _, err := NewClient(tmpDir).ListConfigFile(filepath.Join(tmpDir, "missing")); err == nil {
+		t.Error("ListConfigFile() expected error for missing file, got nil")
+	}
+}
+
+func TestListConfigScope(t *testing.T) {
+	client, _ := setupConfigTestRepo(t)
+	client.ExecGit("config", "gwtm.branch.maxLength", "40")
+	client.ExecGit("config", "--add", "gwtm.branch.prefix", "feat/")
+	client.ExecGit("config", "--add", "gwtm.branch.prefix", "fix/")
+
+	got, err := client.ListConfigScope("local", `^gwtm\.`)
+	if err != nil {
+		t.Fatalf("ListConfigScope() error = %v", err)
+	}
+	want := []ConfigEntry{
+		{Key: "gwtm.branch.maxlength", Value: "40"},
+		{Key: "gwtm.branch.prefix", Value: "feat/"},
+		{Key: "gwtm.branch.prefix", Value: "fix/"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ListConfigScope() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ListConfigScope()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	none, err := client.ListConfigScope("local", `^nothing\.`)
+	if err != nil || len(none) != 0 {
+		t.Errorf("ListConfigScope() with no matches = %v, %v, want empty", none, err)
+	}
+}
+
+func TestGetConfigAll(t *testing.T) {
+	client, _ := setupConfigTestRepo(t)
+	client.ExecGit("config", "--add", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*")
+	client.ExecGit("config", "--add", "remote.origin.fetch", "+refs/tags/*:refs/tags/*")
+
+	got, err := client.GetConfigAll("remote.origin.fetch")
+	if err != nil {
+		t.Fatalf("GetConfigAll() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != "+refs/heads/*:refs/remotes/origin/*" || got[1] != "+refs/tags/*:refs/tags/*" {
+		t.Errorf("GetConfigAll() = %v, want both refspecs in order", got)
+	}
+
+	none, err := client.GetConfigAll("remote.upstream.fetch")
+	if err != nil {
+		t.Fatalf("GetConfigAll() for unset key error = %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("GetConfigAll() for unset key = %v, want empty", none)
+	}
 }
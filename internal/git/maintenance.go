@@ -0,0 +1,52 @@
+package git
+
+import "fmt"
+
+// SupportsMaintenance reports whether git has the maintenance command with
+// scheduling (git 2.31 or later)
+func (c *Client) SupportsMaintenance() bool {
+	return c.AtLeastVersion(2, 31)
+}
+
+// Maintain garbage-collects the repository and rewrites its commit-graph,
+// using git maintenance when available and gc plus commit-graph otherwise
+func (c *Client) Maintain() error {
+	if c.SupportsMaintenance() {
+		_, _, err := c.ExecGit("maintenance", "run", "--task=gc", "--task=commit-graph")
+		if err != nil {
+			return fmt.Errorf("failed to run maintenance: %w", err)
+		}
+		return nil
+	}
+
+	if _, _, err := c.ExecGit("gc", "--quiet"); err != nil {
+		return fmt.Errorf("failed to garbage-collect: %w", err)
+	}
+	if _, _, err := c.ExecGit("commit-graph", "write", "--reachable"); err != nil {
+		return fmt.Errorf("failed to write commit-graph: %w", err)
+	}
+
+	return nil
+}
+
+// MaintenanceStart registers the repository for git's scheduled background
+// maintenance and makes sure the scheduler is set up
+func (c *Client) MaintenanceStart() error {
+	_, _, err := c.ExecGit("maintenance", "start")
+	if err != nil {
+		return fmt.Errorf("failed to schedule maintenance: %w", err)
+	}
+
+	return nil
+}
+
+// MaintenanceUnregister removes the repository from scheduled maintenance.
+// The scheduler itself keeps running for any other registered repositories.
+func (c *Client) MaintenanceUnregister() error {
+	_, _, err := c.ExecGit("maintenance", "unregister")
+	if err != nil {
+		return fmt.Errorf("failed to unschedule maintenance: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMaintain(t *testing.T) {
+	_, tmpDir, defaultBranch := setupTestRepo(t)
+	worktreeDir := filepath.Join(tmpDir, defaultBranch)
+	wt := NewClient(worktreeDir)
+
+	// Leave some loose objects behind
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		os.WriteFile(filepath.Join(worktreeDir, name), []byte(name+"\n"), 0644)
+		wt.ExecGit("add", name)
+		wt.ExecGit("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-m", "Add "+name)
+	}
+
+	bareDir := filepath.Join(tmpDir, ".bare")
+	if err := NewClient(bareDir).Maintain(); err != nil {
+		t.Fatalf("Maintain() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(bareDir, "objects", "info", "commit-graph")); err != nil {
+		// Newer git may write a split commit-graph chain instead
+		if _, err := os.Stat(filepath.Join(bareDir, "objects", "info", "commit-graphs")); err != nil {
+			t.Error("Maintain() did not write a commit-graph")
+		}
+	}
+	packs, _ := filepath.Glob(filepath.Join(bareDir, "objects", "pack", "*.pack"))
+	if len(packs) == 0 {
+		t.Error("Maintain() did not pack the repository")
+	}
+}
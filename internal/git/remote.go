@@ -2,17 +2,29 @@ package git
 
 import (
 	"fmt"
+	"os"
 	"strings"
 )
 
 // Clone clones a repository to the specified target directory
 func (c *Client) Clone(url, target string, bare bool) error {
+	return c.CloneWithReference(url, target, "", bare)
+}
+
+// CloneWithReference clones a repository like Clone, borrowing objects from a
+// local reference repository when one is given and usable. Borrowed objects
+// are not copied, so the reference repository must not be deleted afterwards.
+func (c *Client) CloneWithReference(url, target, reference string, bare bool) error {
 	args := []string{"clone"}
 
 	if bare {
 		args = append(args, "--bare")
 	}
 
+	if reference != "" {
+		args = append(args, "--reference-if-able", reference)
+	}
+
 	args = append(args, url, target)
 
 	_, _, err := c.ExecGit(args...)
@@ -23,6 +35,56 @@ func (c *Client) Clone(url, target string, bare bool) error {
 	return nil
 }
 
+// SyncMirror creates a mirror clone of url at path, or updates it if it
+// already exists. Mirrors are used as shared object caches for clones.
+//
+// Clones borrow objects from the mirror rather than copying them, so the
+// mirror must never lose an object: branches deleted upstream are kept and
+// garbage collection is told never to prune.
+func (c *Client) SyncMirror(url, path string) error {
+	mirror := &Client{WorkDir: path, DryRun: c.DryRun}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		_, _, err := c.ExecGit("clone", "--mirror", url, path)
+		if err != nil {
+			return fmt.Errorf("failed to create mirror: %w", err)
+		}
+		return mirror.protectMirror()
+	}
+
+	// Mirrors created by older versions lack the protection, so apply it on
+	// every update as well
+	if err := mirror.protectMirror(); err != nil {
+		return err
+	}
+
+	_, _, err := mirror.ExecGit("remote", "update")
+	if err != nil {
+		return fmt.Errorf("failed to update mirror: %w", err)
+	}
+
+	return nil
+}
+
+// mirrorProtection is the git configuration that stops a mirror from
+// deleting objects that clones borrowing from it may still need
+var mirrorProtection = [][2]string{
+	{"gc.auto", "0"},
+	{"gc.pruneExpire", "never"},
+	{"gc.reflogExpireUnreachable", "never"},
+	{"fetch.prune", "false"},
+	{"remote.origin.prune", "false"},
+}
+
+func (c *Client) protectMirror() error {
+	for _, setting := range mirrorProtection {
+		if err := c.SetConfig(setting[0], setting[1]); err != nil {
+			return fmt.Errorf("failed to protect mirror: %w", err)
+		}
+	}
+	return nil
+}
+
 // Fetch fetches from the remote repository
 func (c *Client) Fetch(all, prune bool) error {
 	args := []string{"fetch"}
@@ -43,6 +105,91 @@ func (c *Client) Fetch(all, prune bool) error {
 	return nil
 }
 
+// FetchRefspecs fetches the given refspecs from remote, e.g. to fetch refs
+// outside the configured fetch refspec such as pull request heads
+func (c *Client) FetchRefspecs(remote string, refspecs ...string) error {
+	args := append([]string{"fetch", remote}, refspecs...)
+
+	_, _, err := c.ExecGit(args...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", strings.Join(refspecs, " "), err)
+	}
+
+	return nil
+}
+
+// RemoteBranches asks remote which of the named branches it has, returning
+// the names that exist. Unlike BranchExists it queries the remote itself, so
+// it fails when the remote is unreachable.
+func (c *Client) RemoteBranches(remote string, names ...string) ([]string, error) {
+	// Read-only query — run it even in dry-run mode
+	reader := &Client{WorkDir: c.WorkDir}
+	args := []string{"ls-remote", "--heads", remote}
+	for _, name := range names {
+		args = append(args, "refs/heads/"+name)
+	}
+
+	stdout, _, err := reader.ExecGit(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", remote, err)
+	}
+
+	var found []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		_, ref, ok := strings.Cut(line, "\t")
+		if ok {
+			found = append(found, strings.TrimPrefix(ref, "refs/heads/"))
+		}
+	}
+	return found, nil
+}
+
+// FetchBranches fetches only the named branches from origin into their
+// remote-tracking refs, which is much faster than fetching everything
+func (c *Client) FetchBranches(names ...string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	refspecs := make([]string, 0, len(names))
+	for _, name := range names {
+		refspecs = append(refspecs, "+refs/heads/"+name+":refs/remotes/origin/"+name)
+	}
+	return c.FetchRefspecs("origin", refspecs...)
+}
+
+// networkErrorMarkers are fragments of git, curl and ssh messages that mean
+// the remote could not be reached at all
+var networkErrorMarkers = []string{
+	"could not resolve host",
+	"could not resolve hostname",
+	"temporary failure in name resolution",
+	"name or service not known",
+	"failed to connect to",
+	"couldn't connect to server",
+	"could not connect to server",
+	"connection refused",
+	"connection timed out",
+	"operation timed out",
+	"network is unreachable",
+	"no route to host",
+	"ssh: connect to host",
+}
+
+// IsNetworkError reports whether err came from a git command that could not
+// reach the remote, as opposed to one the remote rejected
+func IsNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range networkErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 // Push pushes the specified branch to the remote
 func (c *Client) Push(branch string, setUpstream bool) error {
 	args := []string{"push"}
@@ -61,6 +208,36 @@ func (c *Client) Push(branch string, setUpstream bool) error {
 	return nil
 }
 
+// SetRemoteHead queries the remote for its default branch and records it as
+// refs/remotes/origin/HEAD
+func (c *Client) SetRemoteHead() error {
+	_, _, err := c.ExecGit("remote", "set-head", "origin", "--auto")
+	if err != nil {
+		return fmt.Errorf("failed to set remote HEAD: %w", err)
+	}
+
+	return nil
+}
+
+// LocalDefaultBranch returns the default branch as far as local refs tell:
+// origin/HEAD when it is recorded, otherwise main or master. Unlike
+// DetectDefaultBranch it never contacts origin, so it works offline.
+func (c *Client) LocalDefaultBranch() (string, error) {
+	// Read-only query — run it even in dry-run mode
+	reader := &Client{WorkDir: c.WorkDir}
+	stdout, _, err := reader.ExecGit("symbolic-ref", "--quiet", "refs/remotes/origin/HEAD")
+	if branch := strings.TrimPrefix(strings.TrimSpace(stdout), "refs/remotes/origin/"); err == nil && branch != "" {
+		return branch, nil
+	}
+
+	for _, branch := range []string{"main", "master"} {
+		if reader.BranchExists(branch, false) {
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("failed to detect default branch: origin/HEAD is not recorded locally")
+}
+
 // DetectDefaultBranch detects the default branch of the remote repository
 func (c *Client) DetectDefaultBranch() (string, error) {
 	// Try to get the default branch from symbolic-ref
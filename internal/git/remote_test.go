@@ -1,6 +1,7 @@
 package git
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -205,3 +206,231 @@ func TestDetectDefaultBranch(t *testing.T) {
 		t.Errorf("DetectDefaultBranch() on clone = %s, want to contain 'main' or 'master'", branch2)
 	}
 }
+
+func TestLocalDefaultBranch(t *testing.T) {
+	client, _, defaultBranch := setupRemoteTestRepo(t)
+	client.ExecGit("branch", "trunk")
+	client.Push("trunk", false)
+	client.ExecGit("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/trunk")
+
+	// origin is unreachable, so only local refs can answer
+	client.ExecGit("remote", "set-url", "origin", filepath.Join(t.TempDir(), "missing.git"))
+
+	if branch, err := client.LocalDefaultBranch(); err != nil || branch != "trunk" {
+		t.Errorf("LocalDefaultBranch() = %q, %v, want trunk from origin/HEAD", branch, err)
+	}
+
+	client.ExecGit("symbolic-ref", "--delete", "refs/remotes/origin/HEAD")
+	if branch, err := client.LocalDefaultBranch(); err != nil || branch != defaultBranch {
+		t.Errorf("LocalDefaultBranch() without origin/HEAD = %q, %v, want %s", branch, err, defaultBranch)
+	}
+
+	client.ExecGit("branch", "-m", defaultBranch, "develop")
+	if branch, err := client.LocalDefaultBranch(); err == nil {
+		t.Errorf("LocalDefaultBranch() with no candidates = %q, want error", branch)
+	}
+}
+
+func TestSetRemoteHead(t *testing.T) {
+	client, _, defaultBranch := setupRemoteTestRepo(t)
+	client.Push(defaultBranch, true)
+
+	if client.RefExists("refs/remotes/origin/HEAD") {
+		t.Fatal("refs/remotes/origin/HEAD unexpectedly exists before SetRemoteHead()")
+	}
+
+	if err := client.SetRemoteHead(); err != nil {
+		t.Fatalf("SetRemoteHead() error = %v", err)
+	}
+
+	stdout, _, err := client.ExecGit("symbolic-ref", "refs/remotes/origin/HEAD")
+	if err != nil {
+		t.Fatalf("symbolic-ref after SetRemoteHead() error = %v", err)
+	}
+	if got := strings.TrimSpace(stdout); got != "refs/remotes/origin/"+defaultBranch {
+		t.Errorf("SetRemoteHead() set origin/HEAD to %q, want refs/remotes/origin/%s", got, defaultBranch)
+	}
+}
+
+func TestCloneWithReference(t *testing.T) {
+	client, localDir, defaultBranch := setupRemoteTestRepo(t)
+	client.Push(defaultBranch, true)
+	tmpDir := filepath.Dir(localDir)
+	remoteDir := filepath.Join(tmpDir, "remote.git")
+
+	referenceDir := filepath.Join(tmpDir, "reference.git")
+	if err := NewClient("").SyncMirror(remoteDir, referenceDir); err != nil {
+		t.Fatalf("SyncMirror() create error = %v", err)
+	}
+
+	target := filepath.Join(tmpDir, "clone.git")
+	if err := NewClient("").CloneWithReference(remoteDir, target, referenceDir, true); err != nil {
+		t.Fatalf("CloneWithReference() error = %v", err)
+	}
+
+	alternates, err := os.ReadFile(filepath.Join(target, "objects", "info", "alternates"))
+	if err != nil {
+		t.Fatalf("CloneWithReference() did not write alternates: %v", err)
+	}
+	if !strings.Contains(string(alternates), "reference.git") {
+		t.Errorf("alternates = %q, want it to reference reference.git", alternates)
+	}
+
+	// A missing reference is ignored rather than failing the clone
+	target2 := filepath.Join(tmpDir, "clone2.git")
+	if err := NewClient("").CloneWithReference(remoteDir, target2, filepath.Join(tmpDir, "missing.git"), true); err != nil {
+		t.Errorf("CloneWithReference() with missing reference error = %v", err)
+	}
+}
+
+func TestSyncMirror_Update(t *testing.T) {
+	client, localDir, defaultBranch := setupRemoteTestRepo(t)
+	client.Push(defaultBranch, true)
+	tmpDir := filepath.Dir(localDir)
+	remoteDir := filepath.Join(tmpDir, "remote.git")
+	mirrorDir := filepath.Join(tmpDir, "mirror.git")
+
+	if err := NewClient("").SyncMirror(remoteDir, mirrorDir); err != nil {
+		t.Fatalf("SyncMirror() create error = %v", err)
+	}
+
+	// New branches on the remote appear in the mirror after an update
+	client.ExecGit("branch", "feature-new")
+	client.Push("feature-new", false)
+
+	if err := NewClient("").SyncMirror(remoteDir, mirrorDir); err != nil {
+		t.Fatalf("SyncMirror() update error = %v", err)
+	}
+	if !NewClient(mirrorDir).RefExists("refs/heads/feature-new") {
+		t.Error("SyncMirror() update did not fetch new branch")
+	}
+}
+
+func TestSyncMirror_KeepsBorrowedObjects(t *testing.T) {
+	client, localDir, defaultBranch := setupRemoteTestRepo(t)
+	client.Push(defaultBranch, true)
+	tmpDir := filepath.Dir(localDir)
+	remoteDir := filepath.Join(tmpDir, "remote.git")
+	mirrorDir := filepath.Join(tmpDir, "mirror.git")
+
+	// A branch whose objects exist only upstream and, after the sync, in the mirror
+	client.ExecGit("checkout", "-b", "doomed")
+	os.WriteFile(filepath.Join(localDir, "doomed.txt"), []byte("doomed\n"), 0644)
+	client.ExecGit("add", "doomed.txt")
+	client.ExecGit("commit", "-m", "Doomed commit")
+	client.Push("doomed", false)
+
+	if err := NewClient("").SyncMirror(remoteDir, mirrorDir); err != nil {
+		t.Fatalf("SyncMirror() create error = %v", err)
+	}
+
+	// A file:// URL stops git from hardlinking the objects instead of borrowing them
+	projectDir := filepath.Join(tmpDir, "project.git")
+	if err := NewClient("").CloneWithReference("file://"+remoteDir, projectDir, mirrorDir, true); err != nil {
+		t.Fatalf("CloneWithReference() error = %v", err)
+	}
+
+	// Upstream deletes the branch, the mirror is updated and garbage-collected
+	// as if its unreachable objects had aged past gc's expiry
+	if _, _, err := client.ExecGit("push", "origin", "--delete", "doomed"); err != nil {
+		t.Fatalf("failed to delete upstream branch: %v", err)
+	}
+	if err := NewClient("").SyncMirror(remoteDir, mirrorDir); err != nil {
+		t.Fatalf("SyncMirror() update error = %v", err)
+	}
+	mirror := NewClient(mirrorDir)
+	if _, _, err := mirror.ExecGit("gc", "--quiet", "--prune=now"); err != nil {
+		t.Fatalf("gc in mirror failed: %v", err)
+	}
+
+	if got, _ := mirror.GetConfig("gc.pruneExpire"); got != "never" {
+		t.Errorf("mirror gc.pruneExpire = %q, want never", got)
+	}
+	if got, _ := mirror.GetConfig("gc.auto"); got != "0" {
+		t.Errorf("mirror gc.auto = %q, want 0", got)
+	}
+	if _, _, err := NewClient(projectDir).ExecGit("fsck", "--full"); err != nil {
+		t.Errorf("fsck in the borrowing project failed after the mirror was pruned: %v", err)
+	}
+}
+
+func TestFetchRefspecs(t *testing.T) {
+	client, localDir, defaultBranch := setupRemoteTestRepo(t)
+	client.Push(defaultBranch, true)
+
+	// Publish a ref outside refs/heads, the way forges publish pull requests
+	if _, _, err := client.ExecGit("push", "origin", "HEAD:refs/pull/7/head"); err != nil {
+		t.Fatalf("failed to publish pull request ref: %v", err)
+	}
+
+	clone := NewClient("")
+	cloneDir := filepath.Join(filepath.Dir(localDir), "clone.git")
+	clone.Clone(filepath.Join(filepath.Dir(localDir), "remote.git"), cloneDir, true)
+	clone.WorkDir = cloneDir
+
+	if err := clone.FetchRefspecs("origin", "+refs/pull/7/head:refs/gwtm/pr/7"); err != nil {
+		t.Fatalf("FetchRefspecs() error = %v", err)
+	}
+	if !clone.RefExists("refs/gwtm/pr/7") {
+		t.Error("FetchRefspecs() did not create refs/gwtm/pr/7")
+	}
+
+	if err := clone.FetchRefspecs("origin", "refs/pull/8/head:refs/gwtm/pr/8"); err == nil {
+		t.Error("FetchRefspecs() for a missing ref error = nil, want error")
+	}
+}
+
+func TestRemoteBranchesAndFetchBranches(t *testing.T) {
+	client, localDir, defaultBranch := setupRemoteTestRepo(t)
+	client.Push(defaultBranch, true)
+	client.ExecGit("branch", "feature-a")
+	client.Push("feature-a", false)
+
+	clone := NewClient("")
+	cloneDir := filepath.Join(filepath.Dir(localDir), "clone.git")
+	clone.Clone(filepath.Join(filepath.Dir(localDir), "remote.git"), cloneDir, true)
+	clone.WorkDir = cloneDir
+	clone.ConfigureFetchRefspec()
+
+	found, err := clone.RemoteBranches("origin", "feature-a", "feature-missing", defaultBranch)
+	if err != nil {
+		t.Fatalf("RemoteBranches() error = %v", err)
+	}
+	// ls-remote lists refs in name order
+	if strings.Join(found, ",") != "feature-a,"+defaultBranch {
+		t.Errorf("RemoteBranches() = %v, want feature-a and %s", found, defaultBranch)
+	}
+
+	if err := clone.FetchBranches("feature-a"); err != nil {
+		t.Fatalf("FetchBranches() error = %v", err)
+	}
+	if !clone.RefExists("refs/remotes/origin/feature-a") {
+		t.Error("FetchBranches() did not create refs/remotes/origin/feature-a")
+	}
+	if clone.RefExists("refs/remotes/origin/" + defaultBranch) {
+		t.Error("FetchBranches() fetched a branch it was not asked for")
+	}
+}
+
+func TestIsNetworkError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "dns", err: errors.New("stderr: fatal: unable to access 'https://github.com/a/b.git/': Could not resolve host: github.com"), want: true},
+		{name: "ssh", err: errors.New("stderr: ssh: connect to host github.com port 22: Network is unreachable"), want: true},
+		{name: "refused", err: errors.New("stderr: Failed to connect to 127.0.0.1 port 1: Connection refused"), want: true},
+		{name: "auth", err: errors.New("stderr: git@github.com: Permission denied (publickey)."), want: false},
+		{name: "missing ref", err: errors.New("stderr: fatal: couldn't find remote ref refs/heads/nope"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNetworkError(tt.err); got != tt.want {
+				t.Errorf("IsNetworkError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
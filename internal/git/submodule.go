@@ -0,0 +1,183 @@
+package git
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SubmodulePaths returns the paths of the submodules declared in the
+// .gitmodules file of the working tree at WorkDir, sorted by path
+func (c *Client) SubmodulePaths() ([]string, error) {
+	gitmodules := filepath.Join(c.WorkDir, ".gitmodules")
+	if _, err := os.Stat(gitmodules); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	// Reading .gitmodules has no side effects, so it runs even in dry-run mode
+	reader := &Client{WorkDir: c.WorkDir}
+	stdout, _, err := reader.ExecGit("config", "--file", gitmodules, "--get-regexp", `^submodule\..*\.path$`)
+	if err != nil {
+		if isConfigNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+
+	var paths []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if _, path, found := strings.Cut(line, " "); found && path != "" {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// SubmoduleUpdate initialises and checks out the submodule at path, including
+// any nested submodules. When reference is non-empty, objects are copied from
+// that repository instead of being downloaded again; nothing is borrowed from
+// it afterwards, so it may be removed.
+func (c *Client) SubmoduleUpdate(path, reference string) error {
+	args := []string{"submodule", "update", "--init", "--recursive"}
+	if reference != "" {
+		args = append(args, "--reference", reference, "--dissociate")
+	}
+	args = append(args, "--", path)
+
+	_, _, err := c.ExecGit(args...)
+	if err != nil {
+		return fmt.Errorf("failed to update submodule %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// hasSubmodules reports whether the worktree at path has submodules checked
+// out, or their repositories left in its git directory. git will not move
+// such a worktree, and removes it only when forced.
+func hasSubmodules(path string) bool {
+	// Read-only query — run it even in dry-run mode
+	reader := &Client{WorkDir: path}
+	stdout, _, err := reader.ExecGit("rev-parse", "--absolute-git-dir")
+	if err == nil {
+		if _, err := os.Stat(filepath.Join(strings.TrimSpace(stdout), "modules")); err == nil {
+			return true
+		}
+	}
+
+	paths, _ := reader.SubmodulePaths()
+	for _, p := range paths {
+		if _, err := os.Lstat(filepath.Join(path, filepath.FromSlash(p), ".git")); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCleanWithSubmodules returns an error when the worktree at path or any
+// of its submodules has staged, unstaged or untracked changes
+func checkCleanWithSubmodules(path string) error {
+	// Read-only query — run it even in dry-run mode
+	reader := &Client{WorkDir: path}
+	stdout, _, err := reader.ExecGit("status", "--porcelain", "--ignore-submodules=none")
+	if err != nil {
+		return fmt.Errorf("failed to get worktree status: %w", err)
+	}
+	if strings.TrimSpace(stdout) != "" {
+		return fmt.Errorf("%s or one of its submodules contains modified or untracked files", path)
+	}
+	return nil
+}
+
+// moveWorktreeWithSubmodules moves a worktree the way 'git worktree move'
+// would, then points its submodules' repositories at their new location
+func (c *Client) moveWorktreeWithSubmodules(from, to string) error {
+	if c.DryRun {
+		return nil
+	}
+	if _, err := os.Lstat(to); err == nil {
+		return fmt.Errorf("%s already exists", to)
+	}
+
+	links, err := submoduleGitDirs(from, "")
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(from, to); err != nil {
+		return err
+	}
+	if _, _, err := c.ExecGit("worktree", "repair", to); err != nil {
+		return err
+	}
+
+	for path, gitDir := range links {
+		if err := connectSubmodule(to, path, gitDir); err != nil {
+			return fmt.Errorf("failed to reconnect submodule %s: %w", filepath.ToSlash(path), err)
+		}
+	}
+	return nil
+}
+
+// submoduleGitDirs returns the git directory of every checked-out submodule
+// below dir, nested ones included, keyed by path relative to dir with prefix
+// in front. Submodules keeping their repository in a .git directory of their
+// own are left out, as they move along with the worktree.
+func submoduleGitDirs(dir, prefix string) (map[string]string, error) {
+	paths, err := (&Client{WorkDir: dir}).SubmodulePaths()
+	if err != nil {
+		return nil, err
+	}
+
+	links := map[string]string{}
+	for _, p := range paths {
+		path := filepath.Join(prefix, filepath.FromSlash(p))
+		subDir := filepath.Join(dir, filepath.FromSlash(p))
+		data, err := os.ReadFile(filepath.Join(subDir, ".git"))
+		if err != nil {
+			continue
+		}
+		gitDir, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+		if !found {
+			continue
+		}
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(subDir, gitDir)
+		}
+		links[path] = gitDir
+
+		nested, err := submoduleGitDirs(subDir, path)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(links, nested)
+	}
+	return links, nil
+}
+
+// connectSubmodule links the submodule checked out at path in worktree and
+// its repository at gitDir to each other with relative paths, as git does
+func connectSubmodule(worktree, path, gitDir string) error {
+	dir := filepath.Join(worktree, path)
+	toGitDir, err := filepath.Rel(dir, gitDir)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git"), []byte("gitdir: "+filepath.ToSlash(toGitDir)+"\n"), 0644); err != nil {
+		return err
+	}
+
+	toDir, err := filepath.Rel(gitDir, dir)
+	if err != nil {
+		return err
+	}
+	// Edit the file directly, from the worktree: git would try to enter the
+	// submodule's old work tree first
+	_, _, err = NewClient(worktree).ExecGit("config", "--file", filepath.Join(gitDir, "config"), "core.worktree", filepath.ToSlash(toDir))
+	return err
+}
@@ -0,0 +1,147 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupSubmoduleTestRepo creates a superproject with one submodule at libs/core
+// and returns a fresh clone of it whose submodule is not yet initialised
+func setupSubmoduleTestRepo(t *testing.T) (*Client, string) {
+	tmpDir := t.TempDir()
+
+	// Local submodule URLs are blocked by default since git 2.38.1
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	newRepo := func(name string) *Client {
+		dir := filepath.Join(tmpDir, name)
+		os.MkdirAll(dir, 0755)
+		client := NewClient(dir)
+		client.ExecGit("init")
+		client.ExecGit("config", "user.name", "Test User")
+		client.ExecGit("config", "user.email", "test@example.com")
+		os.WriteFile(filepath.Join(dir, "README.md"), []byte("# "+name+"\n"), 0644)
+		client.ExecGit("add", "README.md")
+		client.ExecGit("commit", "-m", "Initial commit")
+		return client
+	}
+
+	lib := newRepo("lib")
+	super := newRepo("super")
+	super.ExecGit("submodule", "add", lib.WorkDir, "libs/core")
+	super.ExecGit("commit", "-m", "Add submodule")
+
+	cloneDir := filepath.Join(tmpDir, "clone")
+	NewClient("").Clone(super.WorkDir, cloneDir, false)
+
+	return NewClient(cloneDir), tmpDir
+}
+
+func TestSubmodulePaths(t *testing.T) {
+	client, tmpDir := setupSubmoduleTestRepo(t)
+
+	paths, err := client.SubmodulePaths()
+	if err != nil {
+		t.Fatalf("SubmodulePaths() error = %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "libs/core" {
+		t.Errorf("SubmodulePaths() = %v, want [libs/core]", paths)
+	}
+
+	// A working tree without .gitmodules has no submodules
+	none, err := NewClient(filepath.Join(tmpDir, "lib")).SubmodulePaths()
+	if err != nil {
+		t.Fatalf("SubmodulePaths() without .gitmodules error = %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("SubmodulePaths() without .gitmodules = %v, want none", none)
+	}
+}
+
+func TestSubmoduleUpdate(t *testing.T) {
+	client, tmpDir := setupSubmoduleTestRepo(t)
+
+	// Copy objects from the submodule checkout in the original superproject
+	reference := filepath.Join(tmpDir, "super", "libs", "core")
+	if err := client.SubmoduleUpdate("libs/core", reference); err != nil {
+		t.Fatalf("SubmoduleUpdate() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(client.WorkDir, "libs", "core", "README.md")); err != nil {
+		t.Errorf("SubmoduleUpdate() did not check out the submodule: %v", err)
+	}
+
+	stdout, _, err := NewClient(filepath.Join(client.WorkDir, "libs", "core")).ExecGit("rev-parse", "--git-path", "objects/info/alternates")
+	if err != nil {
+		t.Fatalf("failed to locate submodule alternates: %v", err)
+	}
+	alternates := strings.TrimSpace(stdout)
+	if !filepath.IsAbs(alternates) {
+		alternates = filepath.Join(client.WorkDir, "libs", "core", alternates)
+	}
+	// The reference may go away later, so nothing must be left borrowed from it
+	if _, err := os.Stat(alternates); !os.IsNotExist(err) {
+		t.Errorf("SubmoduleUpdate() with reference left %s behind", alternates)
+	}
+
+	if err := client.SubmoduleUpdate("does/not/exist", ""); err == nil {
+		t.Error("SubmoduleUpdate() for unknown path expected error, got nil")
+	}
+}
+
+func TestWorktreeMoveAndRemoveWithSubmodules(t *testing.T) {
+	client, tmpDir := setupSubmoduleTestRepo(t)
+
+	from := filepath.Join(tmpDir, "wt")
+	if err := client.WorktreeAddDetached(from, "HEAD"); err != nil {
+		t.Fatalf("WorktreeAddDetached() error = %v", err)
+	}
+	if err := NewClient(from).SubmoduleUpdate("libs/core", ""); err != nil {
+		t.Fatalf("SubmoduleUpdate() error = %v", err)
+	}
+
+	// git itself refuses to move a worktree with submodules
+	to := filepath.Join(tmpDir, "moved")
+	if err := client.WorktreeMove(from, to); err != nil {
+		t.Fatalf("WorktreeMove() error = %v", err)
+	}
+	submodule := filepath.Join(to, "libs", "core")
+	stdout, _, err := NewClient(submodule).ExecGit("rev-parse", "--show-toplevel")
+	if err != nil {
+		t.Fatalf("submodule is broken after WorktreeMove(): %v", err)
+	}
+	if got, _ := filepath.EvalSymlinks(strings.TrimSpace(stdout)); got != mustEvalSymlinks(t, submodule) {
+		t.Errorf("submodule work tree after WorktreeMove() = %s, want %s", got, submodule)
+	}
+	if clean, err := NewClient(to).IsClean(); err != nil || !clean {
+		t.Errorf("worktree after WorktreeMove() clean = %v, %v, want clean", clean, err)
+	}
+
+	// Changes in a submodule are not thrown away
+	scratch := filepath.Join(submodule, "scratch.txt")
+	os.WriteFile(scratch, []byte("wip\n"), 0644)
+	if err := client.WorktreeRemove(to); err == nil {
+		t.Error("WorktreeRemove() with changes in a submodule error = nil, want error")
+	}
+
+	os.Remove(scratch)
+	if err := client.WorktreeRemove(to); err != nil {
+		t.Fatalf("WorktreeRemove() error = %v", err)
+	}
+	if _, err := os.Stat(to); !os.IsNotExist(err) {
+		t.Error("WorktreeRemove() left the worktree behind")
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatalf("EvalSymlinks(%s) error = %v", path, err)
+	}
+	return resolved
+}
@@ -2,7 +2,9 @@ package git
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // WorktreeAdd creates a new worktree at the specified path for the given branch
@@ -25,27 +27,113 @@ func (c *Client) WorktreeAdd(path, branch string, track bool) error {
 	return nil
 }
 
-// WorktreeList returns a list of all worktrees
+// WorktreeAddDetached creates a new worktree at path with HEAD detached at
+// commitish, e.g. a tag or commit
+func (c *Client) WorktreeAddDetached(path, commitish string) error {
+	_, _, err := c.ExecGit("worktree", "add", "--detach", path, commitish)
+	if err != nil {
+		return fmt.Errorf("failed to add detached worktree: %w", err)
+	}
+
+	return nil
+}
+
+// WorktreeList returns the paths of all checked-out worktrees, leaving out
+// the bare repository and worktrees whose directory no longer exists
 func (c *Client) WorktreeList() ([]string, error) {
-	stdout, _, err := c.ExecGit("worktree", "list")
+	worktrees, err := c.WorktreeListPorcelain()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, wt := range worktrees {
+		if !wt.Bare && !wt.Prunable {
+			paths = append(paths, wt.Path)
+		}
+	}
+
+	return paths, nil
+}
+
+// Worktree describes a single entry of `git worktree list --porcelain`
+type Worktree struct {
+	Path     string // Absolute path of the worktree directory
+	Head     string // Commit checked out in the worktree
+	Branch   string // Short branch name; empty when detached or bare
+	Bare     bool   // True for the bare repository entry itself
+	Detached bool   // True when HEAD is detached
+	Locked   bool   // True when the worktree is locked
+	Prunable bool   // True when the worktree directory no longer exists
+}
+
+// WorktreeListPorcelain returns structured information about all worktrees,
+// including the bare repository entry
+func (c *Client) WorktreeListPorcelain() ([]Worktree, error) {
+	stdout, _, err := c.ExecGit("worktree", "list", "--porcelain")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list worktrees: %w", err)
 	}
 
-	lines := strings.Split(strings.TrimSpace(stdout), "\n")
-	var worktrees []string
-	for _, line := range lines {
-		if line != "" {
-			worktrees = append(worktrees, line)
+	return parseWorktreePorcelain(stdout), nil
+}
+
+// parseWorktreePorcelain parses the blank-line separated records produced by
+// `git worktree list --porcelain`
+func parseWorktreePorcelain(output string) []Worktree {
+	var worktrees []Worktree
+	var current *Worktree
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			current = nil
+			continue
+		}
+
+		attr, value, _ := strings.Cut(line, " ")
+		if attr == "worktree" {
+			worktrees = append(worktrees, Worktree{Path: value})
+			current = &worktrees[len(worktrees)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch attr {
+		case "HEAD":
+			current.Head = value
+		case "branch":
+			current.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "bare":
+			current.Bare = true
+		case "detached":
+			current.Detached = true
+		case "locked":
+			current.Locked = true
+		case "prunable":
+			current.Prunable = true
 		}
 	}
 
-	return worktrees, nil
+	return worktrees
 }
 
 // WorktreeRemove removes the worktree at the specified path
 func (c *Client) WorktreeRemove(path string) error {
-	_, _, err := c.ExecGit("worktree", "remove", path)
+	args := []string{"worktree", "remove", path}
+
+	// git only removes a worktree with submodules when forced, so make the
+	// check it would otherwise make itself, submodules included
+	if hasSubmodules(path) {
+		if err := checkCleanWithSubmodules(path); err != nil {
+			return fmt.Errorf("failed to remove worktree: %w", err)
+		}
+		args = append(args, "--force")
+	}
+
+	_, _, err := c.ExecGit(args...)
 	if err != nil {
 		return fmt.Errorf("failed to remove worktree: %w", err)
 	}
@@ -53,6 +141,24 @@ func (c *Client) WorktreeRemove(path string) error {
 	return nil
 }
 
+// WorktreeMove moves the worktree at from to the new location to
+func (c *Client) WorktreeMove(from, to string) error {
+	// git refuses to move a worktree with submodules, even when forced
+	if hasSubmodules(from) {
+		if err := c.moveWorktreeWithSubmodules(from, to); err != nil {
+			return fmt.Errorf("failed to move worktree: %w", err)
+		}
+		return nil
+	}
+
+	_, _, err := c.ExecGit("worktree", "move", from, to)
+	if err != nil {
+		return fmt.Errorf("failed to move worktree: %w", err)
+	}
+
+	return nil
+}
+
 // WorktreePrune prunes stale worktree references
 func (c *Client) WorktreePrune() error {
 	_, _, err := c.ExecGit("worktree", "prune")
@@ -62,3 +168,125 @@ func (c *Client) WorktreePrune() error {
 
 	return nil
 }
+
+// SupportsRelativeWorktreePaths reports whether git can record the links
+// between a repository and its worktrees as relative paths (git 2.48+)
+func (c *Client) SupportsRelativeWorktreePaths() bool {
+	return c.AtLeastVersion(2, 48)
+}
+
+// WorktreeRepair repairs the administrative links between the repository and
+// its worktrees. Paths of worktrees that have been moved may be given so their
+// links can be re-established. With relativePaths, the links are rewritten as
+// relative paths so that moving the whole project does not break them; only
+// pass it when SupportsRelativeWorktreePaths reports true.
+func (c *Client) WorktreeRepair(relativePaths bool, paths ...string) error {
+	args := []string{"worktree", "repair"}
+	if relativePaths {
+		args = append(args, "--relative-paths")
+	}
+	args = append(args, paths...)
+
+	_, _, err := c.ExecGit(args...)
+	if err != nil {
+		return fmt.Errorf("failed to repair worktrees: %w", err)
+	}
+
+	return nil
+}
+
+// IsClean reports whether the worktree has no staged, unstaged or untracked changes
+func (c *Client) IsClean() (bool, error) {
+	changes, err := c.ChangeCount()
+	return changes == 0, err
+}
+
+// ChangeCount returns the number of files with staged, unstaged or untracked
+// changes in the worktree
+func (c *Client) ChangeCount() (int, error) {
+	// Read-only query — run it even in dry-run mode
+	reader := &Client{WorkDir: c.WorkDir}
+	stdout, _, err := reader.ExecGit("status", "--porcelain")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get worktree status: %w", err)
+	}
+
+	changes := 0
+	for _, line := range strings.Split(stdout, "\n") {
+		if strings.TrimSpace(line) != "" {
+			changes++
+		}
+	}
+	return changes, nil
+}
+
+// LastCommitTime returns when the commit checked out in the worktree was made
+func (c *Client) LastCommitTime() (time.Time, error) {
+	// Read-only query — run it even in dry-run mode
+	reader := &Client{WorkDir: c.WorkDir}
+	stdout, _, err := reader.ExecGit("log", "-1", "--format=%ct", "HEAD")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read last commit: %w", err)
+	}
+
+	seconds, err := strconv.ParseInt(strings.TrimSpace(stdout), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse commit time %q: %w", strings.TrimSpace(stdout), err)
+	}
+
+	return time.Unix(seconds, 0), nil
+}
+
+// ListFiles returns the worktree's tracked and untracked files, relative to
+// the worktree, leaving out ignored files
+func (c *Client) ListFiles() ([]string, error) {
+	return c.lsFiles("--cached", "--others", "--exclude-standard")
+}
+
+// TrackedFiles returns the files in the index, relative to the worktree
+func (c *Client) TrackedFiles() ([]string, error) {
+	return c.lsFiles("--cached")
+}
+
+// IgnoredPaths returns the worktree's ignored files, relative to the
+// worktree. Wholly ignored directories are returned once, with a trailing slash.
+func (c *Client) IgnoredPaths() ([]string, error) {
+	return c.lsFiles("--others", "--ignored", "--exclude-standard", "--directory")
+}
+
+// UntrackedPaths returns the worktree's untracked files, ignored ones
+// included, relative to the worktree. Wholly untracked directories are
+// returned once, with a trailing slash.
+func (c *Client) UntrackedPaths() ([]string, error) {
+	return c.lsFiles("--others", "--directory")
+}
+
+// lsFiles runs git ls-files with args and returns the paths it lists
+func (c *Client) lsFiles(args ...string) ([]string, error) {
+	// Read-only query — run it even in dry-run mode
+	reader := &Client{WorkDir: c.WorkDir}
+	stdout, _, err := reader.ExecGit(append([]string{"ls-files", "-z"}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var files []string
+	for _, file := range strings.Split(stdout, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
+// CleanIgnored deletes the worktree's ignored files and directories, such as
+// build outputs, leaving tracked and untracked files alone
+func (c *Client) CleanIgnored() error {
+	_, _, err := c.ExecGit("clean", "-f", "-d", "-X")
+	if err != nil {
+		return fmt.Errorf("failed to clean ignored files: %w", err)
+	}
+
+	return nil
+}
@@ -3,8 +3,10 @@ package git
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func setupTestRepo(t *testing.T) (*Client, string, string) {
@@ -114,6 +116,11 @@ func TestWorktreeList(t *testing.T) {
 	if !foundFeature {
 		t.Error("WorktreeList() did not include the feature worktree we added")
 	}
+	for _, wt := range worktrees {
+		if wt == bareDir {
+			t.Error("WorktreeList() included the bare repository")
+		}
+	}
 }
 
 func TestWorktreeRemove(t *testing.T) {
@@ -156,3 +163,252 @@ func TestWorktreePrune(t *testing.T) {
 		t.Errorf("WorktreePrune() error = %v", err)
 	}
 }
+
+func TestParseWorktreePorcelain(t *testing.T) {
+	output := `worktree /repo/.bare
+bare
+
+worktree /repo/main
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/main
+
+worktree /repo/v1.0.0
+HEAD 2222222222222222222222222222222222222222
+detached
+
+worktree /repo/gone
+HEAD 3333333333333333333333333333333333333333
+branch refs/heads/feature/gone
+locked
+prunable gitdir file points to non-existent location
+`
+
+	got := parseWorktreePorcelain(output)
+	want := []Worktree{
+		{Path: "/repo/.bare", Bare: true},
+		{Path: "/repo/main", Head: "1111111111111111111111111111111111111111", Branch: "main"},
+		{Path: "/repo/v1.0.0", Head: "2222222222222222222222222222222222222222", Detached: true},
+		{Path: "/repo/gone", Head: "3333333333333333333333333333333333333333", Branch: "feature/gone", Locked: true, Prunable: true},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("parseWorktreePorcelain() returned %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parseWorktreePorcelain()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWorktreeListPorcelain(t *testing.T) {
+	_, tmpDir, defaultBranch := setupTestRepo(t)
+
+	bareClient := NewClient(filepath.Join(tmpDir, ".bare"))
+
+	worktrees, err := bareClient.WorktreeListPorcelain()
+	if err != nil {
+		t.Fatalf("WorktreeListPorcelain() error = %v", err)
+	}
+
+	var foundBare, foundDefault bool
+	for _, wt := range worktrees {
+		if wt.Bare {
+			foundBare = true
+		}
+		if wt.Branch == defaultBranch && filepath.Base(wt.Path) == defaultBranch {
+			foundDefault = true
+		}
+	}
+
+	if !foundBare {
+		t.Error("WorktreeListPorcelain() did not include the bare repository entry")
+	}
+	if !foundDefault {
+		t.Errorf("WorktreeListPorcelain() did not include the %s worktree: %+v", defaultBranch, worktrees)
+	}
+}
+
+func TestWorktreeRepair(t *testing.T) {
+	_, tmpDir, defaultBranch := setupTestRepo(t)
+
+	bareDir := filepath.Join(tmpDir, ".bare")
+	bareClient := NewClient(bareDir)
+
+	// Move the worktree by hand so the repository's link to it goes stale
+	oldPath := filepath.Join(tmpDir, defaultBranch)
+	newPath := filepath.Join(tmpDir, "moved")
+	if err := os.Rename(oldPath, newPath); err != nil {
+		t.Fatalf("failed to move worktree: %v", err)
+	}
+
+	if err := bareClient.WorktreeRepair(false, newPath); err != nil {
+		t.Fatalf("WorktreeRepair() error = %v", err)
+	}
+
+	worktrees, err := bareClient.WorktreeListPorcelain()
+	if err != nil {
+		t.Fatalf("WorktreeListPorcelain() error = %v", err)
+	}
+
+	var found bool
+	for _, wt := range worktrees {
+		if filepath.Base(wt.Path) == "moved" && !wt.Prunable {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("WorktreeRepair() did not re-register the moved worktree: %+v", worktrees)
+	}
+}
+
+func TestIsClean(t *testing.T) {
+	_, tmpDir, defaultBranch := setupTestRepo(t)
+	worktreeDir := filepath.Join(tmpDir, defaultBranch)
+	client := NewClient(worktreeDir)
+
+	clean, err := client.IsClean()
+	if err != nil || !clean {
+		t.Fatalf("IsClean() on fresh repo = %v, %v, want true, nil", clean, err)
+	}
+
+	os.WriteFile(filepath.Join(worktreeDir, "untracked.txt"), []byte("x\n"), 0644)
+	clean, err = client.IsClean()
+	if err != nil || clean {
+		t.Errorf("IsClean() with untracked file = %v, %v, want false, nil", clean, err)
+	}
+
+	os.WriteFile(filepath.Join(worktreeDir, "README.md"), []byte("changed\n"), 0644)
+	if changes, err := client.ChangeCount(); err != nil || changes != 2 {
+		t.Errorf("ChangeCount() with a modified and an untracked file = %d, %v, want 2, nil", changes, err)
+	}
+}
+
+func TestWorktreeAddDetached(t *testing.T) {
+	_, tmpDir, defaultBranch := setupTestRepo(t)
+	client := NewClient(filepath.Join(tmpDir, ".bare"))
+	client.ExecGit("tag", "v1.0.0", defaultBranch)
+
+	path := filepath.Join(tmpDir, "v1.0.0")
+	if err := client.WorktreeAddDetached(path, "v1.0.0"); err != nil {
+		t.Fatalf("WorktreeAddDetached() error = %v", err)
+	}
+
+	worktrees, err := client.WorktreeListPorcelain()
+	if err != nil {
+		t.Fatalf("WorktreeListPorcelain() error = %v", err)
+	}
+	var found bool
+	for _, wt := range worktrees {
+		if filepath.Base(wt.Path) == "v1.0.0" {
+			found = true
+			if !wt.Detached || wt.Branch != "" {
+				t.Errorf("worktree = %+v, want detached with no branch", wt)
+			}
+		}
+	}
+	if !found {
+		t.Error("WorktreeAddDetached() worktree not listed")
+	}
+
+	if err := client.WorktreeAddDetached(filepath.Join(tmpDir, "missing"), "v9.9.9"); err == nil {
+		t.Error("WorktreeAddDetached() with unknown tag error = nil, want error")
+	}
+}
+
+func TestWorktreeMove(t *testing.T) {
+	_, tmpDir, defaultBranch := setupTestRepo(t)
+	client := NewClient(filepath.Join(tmpDir, ".bare"))
+
+	from := filepath.Join(tmpDir, defaultBranch)
+	to := filepath.Join(tmpDir, "moved")
+	if err := client.WorktreeMove(from, to); err != nil {
+		t.Fatalf("WorktreeMove() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(to, "README.md")); err != nil {
+		t.Errorf("WorktreeMove() did not move the worktree: %v", err)
+	}
+	if _, err := os.Stat(from); !os.IsNotExist(err) {
+		t.Error("WorktreeMove() left the old directory behind")
+	}
+}
+
+func TestLastCommitTimeAndListFiles(t *testing.T) {
+	_, tmpDir, defaultBranch := setupTestRepo(t)
+	worktreeDir := filepath.Join(tmpDir, defaultBranch)
+	client := NewClient(worktreeDir)
+
+	committed, err := client.LastCommitTime()
+	if err != nil {
+		t.Fatalf("LastCommitTime() error = %v", err)
+	}
+	if age := time.Since(committed); age < 0 || age > time.Hour {
+		t.Errorf("LastCommitTime() = %v, want about now", committed)
+	}
+
+	os.WriteFile(filepath.Join(worktreeDir, ".gitignore"), []byte("*.log\n"), 0644)
+	os.WriteFile(filepath.Join(worktreeDir, "new.txt"), []byte("x\n"), 0644)
+	os.WriteFile(filepath.Join(worktreeDir, "debug.log"), []byte("x\n"), 0644)
+
+	files, err := client.ListFiles()
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	for _, want := range []string{"README.md", ".gitignore", "new.txt"} {
+		if !slices.Contains(files, want) {
+			t.Errorf("ListFiles() = %v, want it to include %s", files, want)
+		}
+	}
+	if slices.Contains(files, "debug.log") {
+		t.Errorf("ListFiles() = %v, want ignored files left out", files)
+	}
+}
+
+func TestIgnoredPathsAndCleanIgnored(t *testing.T) {
+	_, tmpDir, defaultBranch := setupTestRepo(t)
+	worktreeDir := filepath.Join(tmpDir, defaultBranch)
+	client := NewClient(worktreeDir)
+
+	os.WriteFile(filepath.Join(worktreeDir, ".gitignore"), []byte("build/\n*.log\n"), 0644)
+	os.MkdirAll(filepath.Join(worktreeDir, "build", "out"), 0755)
+	os.WriteFile(filepath.Join(worktreeDir, "build", "out", "app"), []byte("bin"), 0644)
+	os.WriteFile(filepath.Join(worktreeDir, "debug.log"), []byte("log"), 0644)
+	os.WriteFile(filepath.Join(worktreeDir, "notes.txt"), []byte("keep"), 0644)
+
+	ignored, err := client.IgnoredPaths()
+	if err != nil {
+		t.Fatalf("IgnoredPaths() error = %v", err)
+	}
+	if want := []string{"build/", "debug.log"}; !slices.Equal(ignored, want) {
+		t.Errorf("IgnoredPaths() = %v, want %v", ignored, want)
+	}
+
+	// Ignored or not, untracked paths are listed; whole directories once
+	untracked, err := client.UntrackedPaths()
+	if err != nil {
+		t.Fatalf("UntrackedPaths() error = %v", err)
+	}
+	if want := []string{".gitignore", "build/", "debug.log", "notes.txt"}; !slices.Equal(untracked, want) {
+		t.Errorf("UntrackedPaths() = %v, want %v", untracked, want)
+	}
+
+	tracked, err := client.TrackedFiles()
+	if err != nil || !slices.Equal(tracked, []string{"README.md"}) {
+		t.Errorf("TrackedFiles() = %v, %v, want [README.md]", tracked, err)
+	}
+
+	if err := client.CleanIgnored(); err != nil {
+		t.Fatalf("CleanIgnored() error = %v", err)
+	}
+	for _, gone := range []string{"build", "debug.log"} {
+		if _, err := os.Stat(filepath.Join(worktreeDir, gone)); !os.IsNotExist(err) {
+			t.Errorf("CleanIgnored() left %s behind", gone)
+		}
+	}
+	for _, kept := range []string{"notes.txt", ".gitignore", "README.md"} {
+		if _, err := os.Stat(filepath.Join(worktreeDir, kept)); err != nil {
+			t.Errorf("CleanIgnored() removed %s", kept)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package hooks discovers and runs user-provided scripts at points in a
+// worktree's lifecycle.
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// Hook names, matching the script file names looked up in each hook directory
+const (
+	PostSetup  = "post-setup"  // After 'gwtm setup' creates the initial worktree
+	PostCreate = "post-create" // After 'gwtm new-branch' creates a worktree
+	PreRemove  = "pre-remove"  // Before 'gwtm remove' removes a worktree; failure aborts the removal
+	PostRemove = "post-remove" // After 'gwtm remove' has removed a worktree
+)
+
+// RepoHooksDir is where a repository keeps its hooks, relative to a worktree
+const RepoHooksDir = ".gwtm/hooks"
+
+// Env describes the worktree a hook runs for. It is passed to the hook as
+// GWTM_* environment variables.
+type Env struct {
+	Branch       string
+	WorktreePath string
+	ProjectRoot  string
+	BaseBranch   string
+}
+
+// Find returns the executable scripts called name in dirs, in the order the
+// directories are given. Missing directories and non-executable files are ignored.
+func Find(name string, dirs ...string) []string {
+	var scripts []string
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		// Windows has no executable bit, so any regular file counts there
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		scripts = append(scripts, path)
+	}
+	return scripts
+}
+
+// Run runs every script called name found in dirs, in order, from workDir.
+// Output goes straight to the terminal. Run stops at the first script that
+// fails and returns its error.
+func Run(name string, dirs []string, workDir string, env Env) error {
+	for _, script := range Find(name, dirs...) {
+		cmd := exec.Command(script)
+		cmd.Dir = workDir
+		cmd.Env = append(os.Environ(), env.environ(name)...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook %s failed: %w", name, script, err)
+		}
+	}
+	return nil
+}
+
+// environ returns the GWTM_* variables describing env for the hook called name
+func (env Env) environ(name string) []string {
+	return []string{
+		"GWTM_HOOK=" + name,
+		"GWTM_BRANCH=" + env.Branch,
+		"GWTM_WORKTREE_PATH=" + env.WorktreePath,
+		"GWTM_PROJECT_ROOT=" + env.ProjectRoot,
+		"GWTM_BASE_BRANCH=" + env.BaseBranch,
+	}
+}
@@ -0,0 +1,118 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeHook(t *testing.T, dir, name, body string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executable bits are not meaningful on Windows")
+	}
+
+	tmpDir := t.TempDir()
+	repoDir := filepath.Join(tmpDir, "repo")
+	userDir := filepath.Join(tmpDir, "user")
+
+	writeHook(t, repoDir, PostCreate, "true\n", 0755)
+	writeHook(t, userDir, PostCreate, "true\n", 0755)
+	writeHook(t, repoDir, PreRemove, "true\n", 0644) // not executable
+
+	tests := []struct {
+		name string
+		hook string
+		dirs []string
+		want []string
+	}{
+		{
+			name: "repo hooks come before user hooks",
+			hook: PostCreate,
+			dirs: []string{repoDir, userDir},
+			want: []string{filepath.Join(repoDir, PostCreate), filepath.Join(userDir, PostCreate)},
+		},
+		{
+			name: "non-executable scripts are ignored",
+			hook: PreRemove,
+			dirs: []string{repoDir, userDir},
+			want: nil,
+		},
+		{
+			name: "missing and empty directories are ignored",
+			hook: PostCreate,
+			dirs: []string{"", filepath.Join(tmpDir, "missing"), userDir},
+			want: []string{filepath.Join(userDir, PostCreate)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Find(tt.hook, tt.dirs...)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Find() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts in this test are shell scripts")
+	}
+
+	tmpDir := t.TempDir()
+	hooksDir := filepath.Join(tmpDir, "hooks")
+	workDir := filepath.Join(tmpDir, "worktree")
+	os.MkdirAll(workDir, 0755)
+
+	writeHook(t, hooksDir, PostCreate,
+		`printf '%s|%s|%s|%s|%s|%s' "$GWTM_HOOK" "$GWTM_BRANCH" "$GWTM_WORKTREE_PATH" "$GWTM_PROJECT_ROOT" "$GWTM_BASE_BRANCH" "$(pwd)" > env.out`+"\n", 0755)
+
+	env := Env{Branch: "feature-x", WorktreePath: workDir, ProjectRoot: tmpDir, BaseBranch: "main"}
+	if err := Run(PostCreate, []string{hooksDir}, workDir, env); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(workDir, "env.out"))
+	if err != nil {
+		t.Fatalf("hook did not run in the worktree: %v", err)
+	}
+	cwd, _ := filepath.EvalSymlinks(workDir)
+	want := strings.Join([]string{PostCreate, "feature-x", workDir, tmpDir, "main", cwd}, "|")
+	if string(got) != want {
+		t.Errorf("hook saw %q, want %q", got, want)
+	}
+
+	// A failing hook stops the run and is reported
+	writeHook(t, hooksDir, PreRemove, "exit 3\n", 0755)
+	otherDir := filepath.Join(tmpDir, "other")
+	writeHook(t, otherDir, PreRemove, "touch ran\n", 0755)
+
+	err = Run(PreRemove, []string{hooksDir, otherDir}, workDir, env)
+	if err == nil {
+		t.Fatal("Run() with failing hook error = nil, want error")
+	}
+	if !strings.Contains(err.Error(), PreRemove) {
+		t.Errorf("Run() error = %v, want it to name the hook", err)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "ran")); err == nil {
+		t.Error("Run() kept running hooks after one failed")
+	}
+
+	// No hooks is not an error
+	if err := Run(PostRemove, []string{hooksDir}, workDir, env); err != nil {
+		t.Errorf("Run() with no hooks error = %v", err)
+	}
+}
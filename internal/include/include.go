@@ -0,0 +1,275 @@
+// Package include brings untracked local files, such as .env.local or editor
+// settings, from one worktree into another.
+package include
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FileName is the file in a worktree listing the patterns to include
+const FileName = ".worktreeinclude"
+
+// Mode is how matched files are brought into the new worktree
+type Mode string
+
+const (
+	Copy    Mode = "copy"    // Independent copy of each file
+	Symlink Mode = "symlink" // Symbolic link back to the source, so changes are shared
+	Reflink Mode = "reflink" // Copy-on-write clone where the filesystem supports it, otherwise a copy
+)
+
+// ParseMode validates a mode name
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(s)); mode {
+	case Copy, Symlink, Reflink:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid include mode %q: must be copy, symlink or reflink", s)
+	}
+}
+
+// ReadPatterns reads the patterns in an include file, one per line. Blank
+// lines and lines starting with # are ignored. A missing file has no patterns.
+func ReadPatterns(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return patterns, nil
+}
+
+// Match returns the untracked paths of the worktree at root that match any of
+// patterns, relative to root and slash-separated. candidates are the
+// worktree's untracked paths as listed by 'git ls-files --others
+// --directory', so wholly untracked directories such as node_modules/ come
+// with a trailing slash and are not searched unless a pattern reaches into
+// them. A matching directory is returned as a whole rather than file by file.
+//
+// Patterns follow a subset of .gitignore syntax: a pattern without a slash
+// matches a name at any depth, a pattern containing a slash is matched from
+// root, a trailing slash matches directories only, and ** matches any
+// number of directories.
+func Match(root string, candidates, patterns []string) ([]string, error) {
+	var matches []string
+	for len(candidates) > 0 {
+		candidate := candidates[0]
+		candidates = candidates[1:]
+
+		rel := strings.TrimSuffix(candidate, "/")
+		isDir := rel != candidate
+		if slices.ContainsFunc(patterns, func(pattern string) bool { return matchPattern(pattern, rel, isDir) }) {
+			matches = append(matches, rel)
+			continue
+		}
+		if !isDir || !Descend(rel, patterns) {
+			continue
+		}
+
+		entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", rel, err)
+		}
+		for _, entry := range entries {
+			if entry.Name() == ".git" {
+				continue
+			}
+			child := rel + "/" + entry.Name()
+			if entry.IsDir() {
+				child += "/"
+			}
+			candidates = append(candidates, child)
+		}
+	}
+	slices.Sort(matches)
+	return matches, nil
+}
+
+// Descend reports whether a pattern containing a slash could match a path
+// below the directory dir, so that dir needs searching. Patterns without a
+// slash only match an untracked directory as a whole, which keeps
+// directories such as node_modules/ from being searched on every new worktree.
+func Descend(dir string, patterns []string) bool {
+	segments := strings.Split(strings.Trim(dir, "/"), "/")
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		if strings.Contains(pattern, "/") && matchPrefix(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPrefix reports whether path segments could be followed by more
+// segments that, together, match the pattern segments
+func matchPrefix(pattern, segments []string) bool {
+	for len(segments) > 0 {
+		if len(pattern) == 0 {
+			return false
+		}
+		if pattern[0] == "**" {
+			return true
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(pattern) > 0
+}
+
+// matchPattern reports whether the slash-separated relative path rel matches pattern
+func matchPattern(pattern, rel string, isDir bool) bool {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	if pattern == "" || (dirOnly && !isDir) {
+		return false
+	}
+
+	if !strings.Contains(pattern, "/") {
+		// Unanchored: match the last path segment at any depth
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+
+	return matchSegments(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), strings.Split(rel, "/"))
+}
+
+// matchSegments matches path segments against pattern segments, where a **
+// segment matches zero or more path segments
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// ErrExists is returned by Bring when the destination already exists, for
+// example because the file is tracked and already checked out
+var ErrExists = errors.New("destination already exists")
+
+// Bring places the file or directory rel from the srcRoot worktree into the
+// dstRoot worktree using mode. Existing destinations are never overwritten.
+func Bring(srcRoot, dstRoot, rel string, mode Mode) error {
+	src := filepath.Join(srcRoot, filepath.FromSlash(rel))
+	dst := filepath.Join(dstRoot, filepath.FromSlash(rel))
+
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s: %w", rel, ErrExists)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", rel, err)
+	}
+
+	if mode == Symlink {
+		absSrc, err := filepath.Abs(src)
+		if err != nil {
+			return err
+		}
+		if err := os.Symlink(absSrc, dst); err != nil {
+			return fmt.Errorf("failed to link %s: %w", rel, err)
+		}
+		return nil
+	}
+
+	if err := copyTree(src, dst, mode == Reflink); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", rel, err)
+	}
+	return nil
+}
+
+// copyTree copies src to dst, recursing into directories and recreating symlinks
+func copyTree(src, dst string, reflink bool) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	case info.IsDir():
+		if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyTree(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name()), reflink); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return copyFile(src, dst, info.Mode().Perm(), reflink)
+	}
+}
+
+// copyFile copies a regular file, cloning it instead when reflink is set and
+// the filesystem supports it
+func copyFile(src, dst string, perm os.FileMode, reflink bool) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	if reflink && cloneFile(out, in) == nil {
+		return out.Close()
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
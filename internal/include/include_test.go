@@ -0,0 +1,190 @@
+package include
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Mode
+		wantErr bool
+	}{
+		{input: "copy", want: Copy},
+		{input: "symlink", want: Symlink},
+		{input: "Reflink", want: Reflink},
+		{input: "hardlink", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMode(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMode(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadPatterns(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFile(t, tmpDir, FileName, "# local files\n.env.local\n\n  .vscode/settings.json  \n.idea/\n")
+
+	got, err := ReadPatterns(filepath.Join(tmpDir, FileName))
+	if err != nil {
+		t.Fatalf("ReadPatterns() error = %v", err)
+	}
+	want := ".env.local,.vscode/settings.json,.idea/"
+	if strings.Join(got, ",") != want {
+		t.Errorf("ReadPatterns() = %v, want %s", got, want)
+	}
+
+	got, err = ReadPatterns(filepath.Join(tmpDir, "missing"))
+	if err != nil || got != nil {
+		t.Errorf("ReadPatterns() on missing file = %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rel     string
+		isDir   bool
+		want    bool
+	}{
+		{pattern: ".env.local", rel: ".env.local", want: true},
+		{pattern: ".env.local", rel: "services/api/.env.local", want: true},
+		{pattern: ".env*", rel: ".env.development", want: true},
+		{pattern: ".vscode/settings.json", rel: ".vscode/settings.json", want: true},
+		{pattern: ".vscode/settings.json", rel: "sub/.vscode/settings.json", want: false},
+		{pattern: "/.env", rel: ".env", want: true},
+		{pattern: "/.env", rel: "sub/.env", want: false},
+		{pattern: ".idea/", rel: ".idea", isDir: true, want: true},
+		{pattern: ".idea/", rel: ".idea", isDir: false, want: false},
+		{pattern: "**/.env", rel: ".env", want: true},
+		{pattern: "**/.env", rel: "a/b/.env", want: true},
+		{pattern: "config/**/local.yml", rel: "config/local.yml", want: true},
+		{pattern: "config/**/local.yml", rel: "config/dev/eu/local.yml", want: true},
+		{pattern: "config/**/local.yml", rel: "other/local.yml", want: false},
+		{pattern: "*.key", rel: "README.md", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.rel, func(t *testing.T) {
+			if got := matchPattern(tt.pattern, tt.rel, tt.isDir); got != tt.want {
+				t.Errorf("matchPattern(%q, %q, %v) = %v, want %v", tt.pattern, tt.rel, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, ".env.local", "SECRET=1")
+	writeFile(t, root, "services/api/.env.local", "SECRET=2")
+	writeFile(t, root, ".idea/workspace.xml", "<xml/>")
+	writeFile(t, root, ".vscode/settings.json", "{}")
+	writeFile(t, root, ".vscode/extensions.json", "{}")
+	writeFile(t, root, "node_modules/pkg/.env.local", "SECRET=3")
+	writeFile(t, root, "config/dev/local.yml", "a: 1")
+
+	// As listed by git ls-files --others --directory; tracked files such as
+	// README.md are never candidates
+	candidates := []string{".env.local", ".idea/", ".vscode/", "config/", "node_modules/", "services/api/.env.local"}
+	patterns := []string{".env.local", ".idea/", ".vscode/settings.json", "config/**/local.yml"}
+
+	got, err := Match(root, candidates, patterns)
+	if err != nil {
+		t.Fatalf("Match() error = %v", err)
+	}
+
+	// Directories are matched as a whole and searched only when a pattern
+	// with a slash reaches into them, so node_modules/ is left alone
+	want := ".env.local,.idea,.vscode/settings.json,config/dev/local.yml,services/api/.env.local"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Match() = %v, want %s", got, want)
+	}
+}
+
+func TestDescend(t *testing.T) {
+	tests := []struct {
+		dir      string
+		patterns []string
+		want     bool
+	}{
+		{"node_modules", []string{".env.local", "*.log"}, false},
+		{".vscode", []string{".vscode/settings.json"}, true},
+		{"config", []string{"config/**/local.yml"}, true},
+		{"config/dev", []string{"/config/*/local.yml"}, true},
+		{"config/dev", []string{"config/prod/local.yml"}, false},
+		{"apps", []string{"**/.env"}, true},
+		{".idea", []string{".idea/"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := Descend(tt.dir, tt.patterns); got != tt.want {
+			t.Errorf("Descend(%q, %v) = %v, want %v", tt.dir, tt.patterns, got, tt.want)
+		}
+	}
+}
+
+func TestBring(t *testing.T) {
+	src := t.TempDir()
+	writeFile(t, src, ".env.local", "SECRET=1")
+	writeFile(t, src, ".idea/workspace.xml", "<xml/>")
+	writeFile(t, src, "tracked.txt", "source")
+
+	for _, mode := range []Mode{Copy, Symlink, Reflink} {
+		t.Run(string(mode), func(t *testing.T) {
+			dst := t.TempDir()
+			writeFile(t, dst, "tracked.txt", "checked out")
+
+			for _, rel := range []string{".env.local", ".idea"} {
+				if err := Bring(src, dst, rel, mode); err != nil {
+					t.Fatalf("Bring(%s) error = %v", rel, err)
+				}
+			}
+
+			content, err := os.ReadFile(filepath.Join(dst, ".idea", "workspace.xml"))
+			if err != nil || string(content) != "<xml/>" {
+				t.Errorf("Bring() directory content = %q, %v", content, err)
+			}
+
+			info, err := os.Lstat(filepath.Join(dst, ".env.local"))
+			if err != nil {
+				t.Fatalf("Bring() did not create .env.local: %v", err)
+			}
+			if isLink := info.Mode()&os.ModeSymlink != 0; isLink != (mode == Symlink) {
+				t.Errorf("Bring() with mode %s created symlink = %v", mode, isLink)
+			}
+
+			// Existing files are left alone
+			err = Bring(src, dst, "tracked.txt", mode)
+			if !errors.Is(err, ErrExists) {
+				t.Errorf("Bring() over existing file error = %v, want ErrExists", err)
+			}
+			if content, _ := os.ReadFile(filepath.Join(dst, "tracked.txt")); string(content) != "checked out" {
+				t.Errorf("Bring() overwrote existing file: %q", content)
+			}
+		})
+	}
+}
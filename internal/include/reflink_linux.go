@@ -0,0 +1,20 @@
+package include
+
+import (
+	"os"
+	"syscall"
+)
+
+// ficlone is the FICLONE ioctl request from linux/fs.h
+const ficlone = 0x40049409
+
+// cloneFile makes dst share src's data blocks copy-on-write. It fails on
+// filesystems without reflink support (anything but btrfs, XFS, bcachefs...)
+// and across filesystems, in which case the caller falls back to copying.
+func cloneFile(dst, src *os.File) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dst.Fd(), ficlone, src.Fd())
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
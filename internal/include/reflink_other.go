@@ -0,0 +1,13 @@
+//go:build !linux
+
+package include
+
+import (
+	"errors"
+	"os"
+)
+
+// cloneFile is only implemented on Linux; elsewhere reflink falls back to copying
+func cloneFile(dst, src *os.File) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,176 @@
+// Package registry records the projects gwtm has set up, so that commands can
+// work across projects and find any project by name from anywhere.
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Project is one managed project
+type Project struct {
+	Name  string    `json:"name"`          // Directory name, used to refer to the project
+	Path  string    `json:"path"`          // Absolute path of the project root
+	URL   string    `json:"url,omitempty"` // Where the project was cloned from
+	Added time.Time `json:"added"`
+}
+
+// Registry is the list of managed projects, sorted by name
+type Registry struct {
+	Projects []Project `json:"projects"`
+}
+
+// Load reads the registry at path. A missing file is an empty registry.
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Registry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project registry: %w", err)
+	}
+
+	var r Registry
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse project registry %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Save writes the registry to path, replacing the file in one step so that a
+// concurrent reader never sees it half written
+func (r *Registry) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode project registry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write project registry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write project registry: %w", err)
+	}
+	return nil
+}
+
+// Add records p, replacing any entry for the same path. It reports whether
+// the project was new.
+func (r *Registry) Add(p Project) bool {
+	p.Path = filepath.Clean(p.Path)
+	if p.Name == "" {
+		p.Name = filepath.Base(p.Path)
+	}
+
+	i := slices.IndexFunc(r.Projects, func(q Project) bool { return q.Path == p.Path })
+	if i >= 0 {
+		if p.Added.IsZero() {
+			p.Added = r.Projects[i].Added
+		}
+		r.Projects[i] = p
+		return false
+	}
+
+	if p.Added.IsZero() {
+		p.Added = time.Now().UTC().Truncate(time.Second)
+	}
+	r.Projects = append(r.Projects, p)
+	slices.SortStableFunc(r.Projects, func(a, b Project) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return true
+}
+
+// Remove forgets the project at path and reports whether it was registered
+func (r *Registry) Remove(path string) bool {
+	path = filepath.Clean(path)
+	n := len(r.Projects)
+	r.Projects = slices.DeleteFunc(r.Projects, func(p Project) bool { return p.Path == path })
+	return len(r.Projects) != n
+}
+
+// Find returns the project called name. Projects can share a name when the
+// same repository is set up in several places; then the error lists them.
+func (r *Registry) Find(name string) (Project, error) {
+	var matches []Project
+	for _, p := range r.Projects {
+		if p.Name == name {
+			matches = append(matches, p)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return Project{}, fmt.Errorf("no project named %q", name)
+	case 1:
+		return matches[0], nil
+	default:
+		paths := make([]string, len(matches))
+		for i, p := range matches {
+			paths[i] = p.Path
+		}
+		return Project{}, fmt.Errorf("%d projects are named %q: %s", len(matches), name, strings.Join(paths, ", "))
+	}
+}
+
+// mu serialises updates from goroutines in this process, such as parallel
+// setups from a manifest
+var mu sync.Mutex
+
+// Update loads the registry at path, applies fn and saves the result. A lock
+// file next to the registry serialises updates from separate gwtm processes,
+// such as setups started together by a script.
+func Update(path string, fn func(r *Registry)) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	unlock, err := lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	r, err := Load(path)
+	if err != nil {
+		return err
+	}
+	fn(r)
+	return r.Save(path)
+}
+
+// lock waits for the lock on the registry at path and returns the function
+// that releases it. The lock is on a separate file because Save replaces the
+// registry file itself.
+func lock(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock project registry: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock project registry: %w", err)
+	}
+	return func() { f.Close() }, nil
+}
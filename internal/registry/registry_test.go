@@ -0,0 +1,151 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLoadMissing(t *testing.T) {
+	r, err := Load(filepath.Join(t.TempDir(), "projects.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(r.Projects) != 0 {
+		t.Errorf("Load() of a missing file = %v, want an empty registry", r.Projects)
+	}
+}
+
+func TestAddRemoveFind(t *testing.T) {
+	r := &Registry{}
+	path := filepath.FromSlash
+
+	if !r.Add(Project{Path: path("/src/webapp"), URL: "git@github.com:acme/webapp.git"}) {
+		t.Error("Add() of a new project = false, want true")
+	}
+	if !r.Add(Project{Path: path("/src/api/")}) {
+		t.Error("Add() of a second project = false, want true")
+	}
+	if !r.Add(Project{Path: path("/tmp/webapp")}) {
+		t.Error("Add() of a project with a taken name = false, want true")
+	}
+
+	// Re-adding a path updates the entry but keeps when it was first added
+	added := r.Projects[1].Added
+	if r.Add(Project{Path: path("/src/webapp"), URL: "https://github.com/acme/webapp"}) {
+		t.Error("Add() of a registered path = true, want false")
+	}
+
+	var names []string
+	for _, p := range r.Projects {
+		names = append(names, p.Name+"="+p.Path)
+	}
+	if got, want := strings.Join(names, " "), path("api=/src/api webapp=/src/webapp webapp=/tmp/webapp"); got != want {
+		t.Fatalf("Projects = %s, want %s", got, want)
+	}
+	if r.Projects[1].URL != "https://github.com/acme/webapp" || !r.Projects[1].Added.Equal(added) {
+		t.Errorf("updated entry = %+v, want the new URL and the original Added", r.Projects[1])
+	}
+
+	if p, err := r.Find("api"); err != nil || p.Path != path("/src/api") {
+		t.Errorf("Find(api) = %+v, %v, want /src/api", p, err)
+	}
+	if _, err := r.Find("webapp"); err == nil || !strings.Contains(err.Error(), path("/tmp/webapp")) {
+		t.Errorf("Find(webapp) error = %v, want an error listing both paths", err)
+	}
+	if _, err := r.Find("missing"); err == nil {
+		t.Error("Find(missing) error = nil, want an error")
+	}
+
+	if !r.Remove(path("/tmp/webapp/")) {
+		t.Error("Remove() of a registered path = false, want true")
+	}
+	if r.Remove(path("/tmp/webapp")) {
+		t.Error("Remove() of an unregistered path = true, want false")
+	}
+	if p, err := r.Find("webapp"); err != nil || p.Path != path("/src/webapp") {
+		t.Errorf("Find(webapp) after Remove = %+v, %v, want /src/webapp", p, err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "home", "projects.json")
+
+	// Parallel setups must not lose each other's entries
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b", "c", "d"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			err := Update(path, func(r *Registry) {
+				r.Add(Project{Path: filepath.Join("/src", name)})
+			})
+			if err != nil {
+				t.Errorf("Update() error = %v", err)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	r, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(r.Projects) != 4 {
+		t.Errorf("Load() after 4 updates = %d projects, want 4", len(r.Projects))
+	}
+}
+
+func TestUpdate_Processes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "home", "projects.json")
+
+	// Separate gwtm processes, such as setups started by a script, must not
+	// lose each other's entries either
+	var cmds []*exec.Cmd
+	for _, name := range []string{"a", "b", "c", "d"} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestUpdateHelperProcess$")
+		cmd.Env = append(os.Environ(), "GWTM_TEST_REGISTRY="+path, "GWTM_TEST_REGISTRY_NAME="+name)
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Errorf("helper process failed: %v", err)
+		}
+	}
+
+	r, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(r.Projects) != 4*helperUpdates {
+		t.Errorf("Load() after %d updates from 4 processes = %d projects", 4*helperUpdates, len(r.Projects))
+	}
+}
+
+// helperUpdates is how many projects each helper process adds
+const helperUpdates = 25
+
+// TestUpdateHelperProcess adds projects to the registry when run as a helper
+// process by TestUpdate_Processes
+func TestUpdateHelperProcess(t *testing.T) {
+	path := os.Getenv("GWTM_TEST_REGISTRY")
+	if path == "" {
+		t.Skip("only runs as a helper process")
+	}
+	name := os.Getenv("GWTM_TEST_REGISTRY_NAME")
+	for i := range helperUpdates {
+		err := Update(path, func(r *Registry) {
+			r.Add(Project{Path: filepath.Join("/src", fmt.Sprintf("%s%d", name, i))})
+		})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package steps runs multi-step commands as a unit: each completed step is
+// recorded, and when a required step fails the completed steps are undone in
+// reverse order.
+package steps
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Step is one action of a multi-step command
+type Step struct {
+	Emoji    string       // Shown with Name when the step starts; no progress line when empty
+	Name     string       // Describes the step in progress output and the summary
+	Do       func() error // Performs the step
+	Undo     func() error // Reverses Do; nil when the step changes nothing or an earlier step's Undo reverts it too
+	Optional bool         // A failure is recorded but does not roll back the other steps
+}
+
+// State is the final state of a step
+type State int
+
+const (
+	Done           State = iota // Completed and kept
+	Failed                      // Did not complete
+	RolledBack                  // Completed, then undone after a later failure
+	RollbackFailed              // Completed, but undoing it failed
+)
+
+// Result records what happened to one step
+type Result struct {
+	Step  Step
+	State State
+	Err   error // Why the step failed, or why undoing it failed
+}
+
+// Runner runs steps one at a time and remembers how to undo them
+type Runner struct {
+	status  func(emoji, message string)
+	results []Result
+}
+
+// NewRunner creates a Runner that reports progress through status
+func NewRunner(status func(emoji, message string)) *Runner {
+	return &Runner{status: status}
+}
+
+// Run performs step and returns its error unchanged. When a required step
+// fails, every completed step is undone in reverse order first; an optional
+// step's failure is only recorded.
+func (r *Runner) Run(step Step) error {
+	if step.Emoji != "" {
+		r.status(step.Emoji, step.Name)
+	}
+
+	err := step.Do()
+	if err == nil {
+		r.results = append(r.results, Result{Step: step, State: Done})
+		return nil
+	}
+
+	r.results = append(r.results, Result{Step: step, State: Failed, Err: err})
+	if !step.Optional {
+		r.Rollback()
+	}
+	return err
+}
+
+// Rollback undoes completed steps in reverse order, carrying on past steps
+// whose undo fails so as much as possible is reverted. Run calls it when a
+// required step fails; call it directly when something between steps fails.
+func (r *Runner) Rollback() {
+	for i := len(r.results) - 1; i >= 0; i-- {
+		result := &r.results[i]
+		if result.State != Done {
+			continue
+		}
+		if result.Step.Undo == nil {
+			result.State = RolledBack
+			continue
+		}
+		if err := result.Step.Undo(); err != nil {
+			result.State = RollbackFailed
+			result.Err = err
+			continue
+		}
+		result.State = RolledBack
+	}
+}
+
+// Results returns what happened to each step run so far, in order
+func (r *Runner) Results() []Result {
+	return r.results
+}
+
+// PrintSummary reports the final state of every step run so far, so the user
+// can see exactly what was kept and what was undone
+func (r *Runner) PrintSummary() {
+	r.status("📊", "Summary:")
+	for _, result := range r.results {
+		switch result.State {
+		case Done:
+			r.status("  ✅", result.Step.Name)
+		case Failed:
+			r.status("  ❌", fmt.Sprintf("%s — failed: %s", result.Step.Name, firstLine(result.Err)))
+		case RolledBack:
+			r.status("  ↩️", result.Step.Name+" — undone")
+		case RollbackFailed:
+			r.status("  ⚠️", fmt.Sprintf("%s — could not be undone: %s", result.Step.Name, firstLine(result.Err)))
+		}
+	}
+}
+
+// firstLine returns the first line of err's message, which is enough for a
+// summary; the full message was printed when the step failed
+func firstLine(err error) string {
+	line, _, _ := strings.Cut(err.Error(), "\n")
+	return line
+}
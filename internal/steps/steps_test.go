@@ -0,0 +1,128 @@
+package steps
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunner(t *testing.T) {
+	errBoom := errors.New("boom")
+	errStuck := errors.New("stuck")
+
+	tests := []struct {
+		name       string
+		steps      []testStep
+		wantErr    error
+		wantStates []State
+		wantUndone []string // Names whose Undo ran, in order
+	}{
+		{
+			name:       "all succeed",
+			steps:      []testStep{{name: "a"}, {name: "b"}},
+			wantStates: []State{Done, Done},
+		},
+		{
+			name:       "required failure undoes completed steps in reverse",
+			steps:      []testStep{{name: "a"}, {name: "b"}, {name: "c", fail: errBoom}},
+			wantErr:    errBoom,
+			wantStates: []State{RolledBack, RolledBack, Failed},
+			wantUndone: []string{"b", "a"},
+		},
+		{
+			name:       "optional failure keeps completed steps",
+			steps:      []testStep{{name: "a"}, {name: "b", fail: errBoom, optional: true}},
+			wantErr:    errBoom,
+			wantStates: []State{Done, Failed},
+		},
+		{
+			name:       "failed undo is recorded and the rest still run",
+			steps:      []testStep{{name: "a"}, {name: "b", undoFail: errStuck}, {name: "c", fail: errBoom}},
+			wantErr:    errBoom,
+			wantStates: []State{RolledBack, RollbackFailed, Failed},
+			wantUndone: []string{"b", "a"},
+		},
+		{
+			name:       "steps without Undo count as undone",
+			steps:      []testStep{{name: "a"}, {name: "b", noUndo: true}, {name: "c", fail: errBoom}},
+			wantErr:    errBoom,
+			wantStates: []State{RolledBack, RolledBack, Failed},
+			wantUndone: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var undone []string
+			runner := NewRunner(func(string, string) {})
+
+			var err error
+			for _, step := range tt.steps {
+				if err = runner.Run(step.build(&undone)); err != nil {
+					break
+				}
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+			results := runner.Results()
+			if len(results) != len(tt.wantStates) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.wantStates))
+			}
+			for i, result := range results {
+				if result.State != tt.wantStates[i] {
+					t.Errorf("step %s state = %d, want %d", result.Step.Name, result.State, tt.wantStates[i])
+				}
+			}
+			if strings.Join(undone, ",") != strings.Join(tt.wantUndone, ",") {
+				t.Errorf("undone = %v, want %v", undone, tt.wantUndone)
+			}
+		})
+	}
+}
+
+// testStep describes a Step whose Do and Undo succeed unless told otherwise
+type testStep struct {
+	name     string
+	fail     error // Returned by Do
+	undoFail error // Returned by Undo
+	noUndo   bool
+	optional bool
+}
+
+func (s testStep) build(undone *[]string) Step {
+	step := Step{
+		Name:     s.name,
+		Do:       func() error { return s.fail },
+		Optional: s.optional,
+	}
+	if !s.noUndo {
+		step.Undo = func() error {
+			*undone = append(*undone, s.name)
+			return s.undoFail
+		}
+	}
+	return step
+}
+
+func TestPrintSummary(t *testing.T) {
+	var lines []string
+	runner := NewRunner(func(emoji, message string) {
+		lines = append(lines, emoji+" "+message)
+	})
+
+	runner.Run(Step{Emoji: "📂", Name: "create", Do: func() error { return nil }, Undo: func() error { return nil }})
+	runner.Run(Step{Name: "clone", Do: func() error { return errors.New("network down\ndetails") }})
+	lines = nil
+	runner.PrintSummary()
+
+	want := []string{
+		"📊 Summary:",
+		"  ↩️ create — undone",
+		"  ❌ clone — failed: network down",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("PrintSummary() printed:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
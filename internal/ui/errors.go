@@ -3,8 +3,13 @@ package ui
 import (
 	"fmt"
 	"os"
+	"sync/atomic"
 )
 
+// errorCount counts the errors printed, so the process can exit non-zero
+// even though commands report errors rather than return them
+var errorCount atomic.Int64
+
 // FormatError formats an error message with actionable guidance
 // Returns a formatted string with ❌ emoji for error and 💡 emoji for guidance
 func FormatError(err error, guidance string) string {
@@ -13,5 +18,11 @@ func FormatError(err error, guidance string) string {
 
 // PrintError prints a formatted error message to stderr
 func PrintError(err error, guidance string) {
+	errorCount.Add(1)
 	fmt.Fprintln(os.Stderr, FormatError(err, guidance))
 }
+
+// ErrorCount returns the number of errors printed by PrintError so far
+func ErrorCount() int {
+	return int(errorCount.Load())
+}
@@ -58,7 +58,11 @@ func TestPrintError(t *testing.T) {
 	old := os.Stderr
 	os.Stderr = w
 
+	before := ErrorCount()
 	PrintError(errors.New("test error"), "test guidance")
+	if got := ErrorCount(); got != before+1 {
+		t.Errorf("ErrorCount() after PrintError() = %d, want %d", got, before+1)
+	}
 
 	w.Close()
 	os.Stderr = old
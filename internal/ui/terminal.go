@@ -0,0 +1,14 @@
+package ui
+
+import "os"
+
+// IsTerminal reports whether f is an interactive terminal rather than a
+// pipe, file or /dev/null
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// /dev/null is a character device too, so ask the terminal driver
+	return isTerminalFd(f.Fd())
+}
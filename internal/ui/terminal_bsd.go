@@ -0,0 +1,15 @@
+//go:build darwin || freebsd || netbsd || openbsd
+
+package ui
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// isTerminalFd reports whether the terminal driver recognises fd
+func isTerminalFd(fd uintptr) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGETA, uintptr(unsafe.Pointer(&termios)))
+	return errno == 0
+}
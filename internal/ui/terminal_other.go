@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !windows
+
+package ui
+
+// isTerminalFd trusts the character-device check on platforms without a
+// terminal query
+func isTerminalFd(fd uintptr) bool {
+	return true
+}